@@ -0,0 +1,126 @@
+package runagent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/runagent-dev/runagent-go/internal/constants"
+)
+
+// AgentConfig is the metadata an agent declares in its runagent.config.json.
+// It describes the agent without a running server.
+type AgentConfig struct {
+	AgentID     string `json:"agent_id,omitempty"`
+	AgentName   string `json:"agent_name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Framework   string `json:"framework,omitempty"`
+	Template    string `json:"template,omitempty"`
+	Version     string `json:"version,omitempty"`
+	CreatedAt   string `json:"created_at,omitempty"`
+	// AgentArchitecture lists the agent's entrypoints.
+	AgentArchitecture AgentArchitecture `json:"agent_architecture"`
+	// Path is the file the config was read from.
+	Path string `json:"-"`
+}
+
+// LoadAgentConfig reads runagent.config.json from path, which may be the
+// file itself or the agent's directory.
+func LoadAgentConfig(path string) (*AgentConfig, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, constants.AgentConfigFileName)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, newError(
+				ErrorTypeValidation,
+				fmt.Sprintf("agent config %s not found", path),
+				withCode("AGENT_CONFIG_NOT_FOUND"),
+				withCause(err),
+				withSuggestion(fmt.Sprintf("Pass the agent directory or its %s", constants.AgentConfigFileName)),
+			)
+		}
+		return nil, newError(ErrorTypeValidation, fmt.Sprintf("failed to read agent config %s", path), withCause(err))
+	}
+
+	var cfg AgentConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, newError(
+			ErrorTypeValidation,
+			fmt.Sprintf("invalid agent config %s", path),
+			withCode("INVALID_AGENT_CONFIG"),
+			withCause(err),
+		)
+	}
+	cfg.Path = path
+	return &cfg, nil
+}
+
+// LoadLocalAgentConfig reads the runagent.config.json of a locally
+// registered agent, found through the registry's agent path.
+func LoadLocalAgentConfig(agentID string) (*AgentConfig, error) {
+	agent, err := discoverLocalAgent(agentID)
+	if err != nil {
+		return nil, err
+	}
+	if agent.AgentPath == "" {
+		return nil, newError(
+			ErrorTypeValidation,
+			fmt.Sprintf("agent %s has no path in the local registry", agentID),
+			withCode("AGENT_CONFIG_NOT_FOUND"),
+			withSuggestion("Re-register the agent with its directory, or call LoadAgentConfig with the path"),
+		)
+	}
+	return LoadAgentConfig(agent.AgentPath)
+}
+
+// Architecture returns the declared entrypoints as an AgentArchitecture,
+// as GetArchitecture would for a running agent.
+func (a *AgentConfig) Architecture() *AgentArchitecture {
+	return &AgentArchitecture{
+		AgentID:     firstNonEmpty(a.AgentArchitecture.AgentID, a.AgentID),
+		Entrypoints: append([]EntryPoint(nil), a.AgentArchitecture.Entrypoints...),
+	}
+}
+
+// Entrypoint returns the entrypoint declared with tag.
+func (a *AgentConfig) Entrypoint(tag string) (EntryPoint, bool) {
+	for _, ep := range a.AgentArchitecture.Entrypoints {
+		if ep.Tag == tag {
+			return ep, true
+		}
+	}
+	return EntryPoint{}, false
+}
+
+// CheckEntrypoint fails with ENTRYPOINT_NOT_FOUND when the config declares
+// entrypoints and none has tag. A config without entrypoints accepts any tag.
+func (a *AgentConfig) CheckEntrypoint(tag string) error {
+	entrypoints := a.AgentArchitecture.Entrypoints
+	if len(entrypoints) == 0 {
+		return nil
+	}
+	if _, ok := a.Entrypoint(tag); ok {
+		return nil
+	}
+	tags := make([]string, len(entrypoints))
+	for i, ep := range entrypoints {
+		tags[i] = ep.Tag
+	}
+	return newError(
+		ErrorTypeValidation,
+		fmt.Sprintf("entrypoint %s is not declared in %s", tag, a.Path),
+		withCode("ENTRYPOINT_NOT_FOUND"),
+		withDetails(map[string]interface{}{"available_tags": tags}),
+		withSuggestion(fmt.Sprintf("Use one of: %s", strings.Join(tags, ", "))),
+	)
+}
+
+// Streaming reports whether the entrypoint must be invoked with RunStream.
+func (e EntryPoint) Streaming() bool {
+	return isStreamTag(e.Tag)
+}
@@ -0,0 +1,462 @@
+package runagent
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/runagent-dev/runagent-go/internal/constants"
+)
+
+// attachmentRefType marks attachment references inside run payloads and outputs.
+const attachmentRefType = "runagent_attachment"
+
+// Attachment is a file or binary input. Pass it like any other argument,
+// e.g. Kw("document", att); the client uploads it before the run and sends
+// a reference in its place.
+type Attachment struct {
+	Name     string
+	MIMEType string
+	// SHA256 optionally pins the expected hex checksum of the content.
+	SHA256 string
+
+	reader io.Reader
+	path   string
+	size   int64
+}
+
+// AttachmentRef references uploaded content in run payloads and outputs.
+type AttachmentRef struct {
+	Type     string `json:"type"`
+	ID       string `json:"id,omitempty"`
+	Name     string `json:"name,omitempty"`
+	MIMEType string `json:"mime_type,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+	SHA256   string `json:"sha256,omitempty"`
+	URL      string `json:"url,omitempty"`
+}
+
+// AttachmentFromReader wraps a reader. The content is consumed on upload.
+func AttachmentFromReader(name, mimeType string, r io.Reader) *Attachment {
+	return &Attachment{Name: name, MIMEType: mimeType, reader: r, size: -1}
+}
+
+// AttachmentFromBytes wraps in-memory content.
+func AttachmentFromBytes(name, mimeType string, data []byte) *Attachment {
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	return &Attachment{Name: name, MIMEType: mimeType, reader: bytes.NewReader(data), size: int64(len(data))}
+}
+
+// AttachmentFromFile references a file on disk. An empty mimeType is
+// inferred from the file extension.
+func AttachmentFromFile(path, mimeType string) (*Attachment, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, newError(ErrorTypeValidation, fmt.Sprintf("cannot read attachment %s", path), withCause(err))
+	}
+	if info.IsDir() {
+		return nil, newError(ErrorTypeValidation, fmt.Sprintf("attachment %s is a directory", path))
+	}
+	if mimeType == "" {
+		mimeType = mime.TypeByExtension(filepath.Ext(path))
+	}
+	return &Attachment{Name: filepath.Base(path), MIMEType: mimeType, path: path, size: info.Size()}, nil
+}
+
+// IsAttachment reports whether a decoded output value is an attachment reference.
+func IsAttachment(value interface{}) (*AttachmentRef, bool) {
+	m, ok := value.(map[string]interface{})
+	if !ok || m["type"] != attachmentRefType {
+		return nil, false
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, false
+	}
+	var ref AttachmentRef
+	if err := json.Unmarshal(data, &ref); err != nil || (ref.ID == "" && ref.URL == "") {
+		return nil, false
+	}
+	return &ref, true
+}
+
+// FindAttachments collects attachment references anywhere in a decoded output.
+func FindAttachments(output interface{}) []*AttachmentRef {
+	var refs []*AttachmentRef
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		if ref, ok := IsAttachment(v); ok {
+			refs = append(refs, ref)
+			return
+		}
+		switch typed := v.(type) {
+		case map[string]interface{}:
+			for _, item := range typed {
+				walk(item)
+			}
+		case []interface{}:
+			for _, item := range typed {
+				walk(item)
+			}
+		}
+	}
+	walk(output)
+	return refs
+}
+
+// DownloadAttachment streams an attachment from a run output into w,
+// enforcing the size limit and verifying the checksum when one is known.
+func (c *RunAgentClient) DownloadAttachment(ctx context.Context, ref *AttachmentRef, w io.Writer) (int64, error) {
+	if ref == nil || (ref.ID == "" && ref.URL == "") {
+		return 0, newError(ErrorTypeValidation, "attachment reference requires an id or url")
+	}
+
+	endpoint := ref.URL
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("%s/agents/%s/attachments/%s", c.baseRESTURL, c.agentID, ref.ID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return 0, newError(ErrorTypeUnknown, "failed to create request", withCause(err))
+	}
+	req.Header.Set("User-Agent", userAgent())
+	// Only send credentials to the RunAgent service itself.
+	if strings.HasPrefix(endpoint, c.baseRESTURL) {
+		if err := c.applyAuth(req); err != nil {
+			return 0, err
+		}
+	}
+
+	resp, err := c.doAuthorized(c.httpClient, req)
+	if err != nil {
+		return 0, newError(ErrorTypeConnection, "failed to download attachment", withCause(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		return 0, translateHTTPError(resp.StatusCode, resp.Header, body)
+	}
+	if ref.Size > 0 && ref.Size > c.maxAttachmentBytes {
+		return 0, newAttachmentTooLargeError(ref.Name, ref.Size, c.maxAttachmentBytes)
+	}
+
+	hasher := sha256.New()
+	limited := io.LimitReader(resp.Body, c.maxAttachmentBytes+1)
+	n, err := io.Copy(io.MultiWriter(w, hasher), limited)
+	if err != nil {
+		return n, newError(ErrorTypeConnection, "failed to read attachment", withCause(err))
+	}
+	if n > c.maxAttachmentBytes {
+		return n, newAttachmentTooLargeError(ref.Name, n, c.maxAttachmentBytes)
+	}
+	if err := verifyChecksum(ref.Name, ref.SHA256, hasher); err != nil {
+		return n, err
+	}
+	return n, nil
+}
+
+// resolveAttachments uploads every *Attachment in the input and replaces it
+// with an AttachmentRef.
+func (c *RunAgentClient) resolveAttachments(ctx context.Context, input *RunInput) error {
+	for i, arg := range input.InputArgs {
+		resolved, err := c.resolveAttachmentValue(ctx, arg)
+		if err != nil {
+			return err
+		}
+		input.InputArgs[i] = resolved
+	}
+	for k, v := range input.InputKwargs {
+		resolved, err := c.resolveAttachmentValue(ctx, v)
+		if err != nil {
+			return err
+		}
+		input.InputKwargs[k] = resolved
+	}
+	return nil
+}
+
+func (c *RunAgentClient) resolveAttachmentValue(ctx context.Context, value interface{}) (interface{}, error) {
+	switch typed := value.(type) {
+	case *Attachment:
+		return c.uploadAttachment(ctx, typed)
+	case []interface{}:
+		// Copy rather than mutate caller-owned containers.
+		out := make([]interface{}, len(typed))
+		for i, item := range typed {
+			resolved, err := c.resolveAttachmentValue(ctx, item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = resolved
+		}
+		return out, nil
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(typed))
+		for k, item := range typed {
+			resolved, err := c.resolveAttachmentValue(ctx, item)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = resolved
+		}
+		return out, nil
+	default:
+		return value, nil
+	}
+}
+
+// attachmentCacheValue replaces attachments in value with their content
+// digests for cache keys. It reports false when a digest would consume a
+// reader the upload still needs.
+func attachmentCacheValue(value interface{}) (interface{}, bool) {
+	switch typed := value.(type) {
+	case *Attachment:
+		digest, ok := typed.digest()
+		if !ok {
+			return nil, false
+		}
+		return map[string]interface{}{
+			"type":      attachmentRefType,
+			"name":      typed.Name,
+			"mime_type": typed.MIMEType,
+			"sha256":    digest,
+		}, true
+	case []interface{}:
+		out := make([]interface{}, len(typed))
+		for i, item := range typed {
+			resolved, ok := attachmentCacheValue(item)
+			if !ok {
+				return nil, false
+			}
+			out[i] = resolved
+		}
+		return out, true
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(typed))
+		for k, item := range typed {
+			resolved, ok := attachmentCacheValue(item)
+			if !ok {
+				return nil, false
+			}
+			out[k] = resolved
+		}
+		return out, true
+	default:
+		return value, true
+	}
+}
+
+// digest returns the hex SHA-256 of the content without consuming it: the
+// pinned SHA256, a hash of the file, or of a seekable reader rewound after.
+func (att *Attachment) digest() (string, bool) {
+	if att.SHA256 != "" {
+		return strings.ToLower(att.SHA256), true
+	}
+	hasher := sha256.New()
+	switch {
+	case att.path != "":
+		file, err := os.Open(att.path)
+		if err != nil {
+			return "", false
+		}
+		defer file.Close()
+		if _, err := io.Copy(hasher, file); err != nil {
+			return "", false
+		}
+	default:
+		seeker, ok := att.reader.(io.ReadSeeker)
+		if !ok {
+			return "", false
+		}
+		start, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return "", false
+		}
+		_, err = io.Copy(hasher, seeker)
+		if _, seekErr := seeker.Seek(start, io.SeekStart); err != nil || seekErr != nil {
+			return "", false
+		}
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), true
+}
+
+// uploadAttachment streams the content as multipart/form-data to the
+// attachments endpoint, hashing and size-checking it on the way.
+func (c *RunAgentClient) uploadAttachment(ctx context.Context, att *Attachment) (*AttachmentRef, error) {
+	if att.size > c.maxAttachmentBytes {
+		return nil, newAttachmentTooLargeError(att.Name, att.size, c.maxAttachmentBytes)
+	}
+
+	source := att.reader
+	if att.path != "" {
+		file, err := os.Open(att.path)
+		if err != nil {
+			return nil, newError(ErrorTypeValidation, fmt.Sprintf("cannot read attachment %s", att.path), withCause(err))
+		}
+		defer file.Close()
+		source = file
+	}
+	if source == nil {
+		return nil, newError(ErrorTypeValidation, "attachment has no content", withSuggestion("Create attachments with AttachmentFromFile/Bytes/Reader"))
+	}
+
+	name := att.Name
+	if name == "" {
+		name = "attachment"
+	}
+	mimeType := att.MIMEType
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+
+	hasher := sha256.New()
+	counter := &countingReader{r: io.TeeReader(io.LimitReader(source, c.maxAttachmentBytes+1), hasher)}
+
+	pr, pw := io.Pipe()
+	form := multipart.NewWriter(pw)
+	go func() {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, strings.ReplaceAll(name, `"`, "")))
+		header.Set("Content-Type", mimeType)
+		part, err := form.CreatePart(header)
+		if err == nil {
+			_, err = io.Copy(part, counter)
+		}
+		if err == nil && counter.n > c.maxAttachmentBytes {
+			err = newAttachmentTooLargeError(name, counter.n, c.maxAttachmentBytes)
+		}
+		if err == nil {
+			err = form.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	endpoint := fmt.Sprintf("%s/agents/%s/attachments", c.baseRESTURL, c.agentID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, pr)
+	if err != nil {
+		pr.Close()
+		return nil, newError(ErrorTypeUnknown, "failed to create request", withCause(err))
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("User-Agent", userAgent())
+	if err := c.applyAuth(req); err != nil {
+		pr.Close()
+		return nil, err
+	}
+
+	resp, err := c.doAuthorized(c.httpClient, req)
+	if err != nil {
+		var runErr *RunAgentError
+		if errors.As(err, &runErr) {
+			return nil, runErr
+		}
+		return nil, newError(ErrorTypeConnection, "failed to upload attachment", withCause(err))
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, newError(ErrorTypeUnknown, "failed to read response body", withCause(err))
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, translateHTTPError(resp.StatusCode, resp.Header, body)
+	}
+
+	if err := verifyChecksum(name, att.SHA256, hasher); err != nil {
+		return nil, err
+	}
+	checksum := hex.EncodeToString(hasher.Sum(nil))
+
+	ref := &AttachmentRef{}
+	if result, err := parseRunResponse(resp.StatusCode, body); err == nil {
+		if data, err := json.Marshal(result); err == nil {
+			json.Unmarshal(data, ref)
+		}
+	} else {
+		return nil, err
+	}
+	if ref.ID == "" {
+		var alt struct {
+			AttachmentID string `json:"attachment_id"`
+		}
+		json.Unmarshal(body, &alt)
+		ref.ID = alt.AttachmentID
+	}
+	if ref.ID == "" && ref.URL == "" {
+		return nil, newError(ErrorTypeServer, "attachment upload response did not include an id", withCode("ATTACHMENT_UPLOAD_FAILED"))
+	}
+	if ref.SHA256 != "" && !strings.EqualFold(ref.SHA256, checksum) {
+		return nil, newChecksumError(name, ref.SHA256, checksum)
+	}
+
+	ref.Type = attachmentRefType
+	ref.Name = name
+	ref.MIMEType = mimeType
+	ref.Size = counter.n
+	ref.SHA256 = checksum
+	return ref, nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func verifyChecksum(name, expected string, hasher hash.Hash) error {
+	if expected == "" {
+		return nil
+	}
+	actual := hex.EncodeToString(hasher.Sum(nil))
+	if !strings.EqualFold(expected, actual) {
+		return newChecksumError(name, expected, actual)
+	}
+	return nil
+}
+
+func newChecksumError(name, expected, actual string) *RunAgentError {
+	return newError(
+		ErrorTypeValidation,
+		fmt.Sprintf("attachment %s failed checksum verification", name),
+		withCode("ATTACHMENT_CHECKSUM_MISMATCH"),
+		withDetails(map[string]interface{}{"expected": expected, "actual": actual}),
+	)
+}
+
+func newAttachmentTooLargeError(name string, size, limit int64) *RunAgentError {
+	return newError(
+		ErrorTypeValidation,
+		fmt.Sprintf("attachment %s exceeds the %d byte limit", name, limit),
+		withCode("ATTACHMENT_TOO_LARGE"),
+		withDetails(map[string]interface{}{"size": size, "limit": limit}),
+		withSuggestion("Raise Config.MaxAttachmentBytes or send a smaller file"),
+	)
+}
+
+func resolveMaxAttachmentBytes(configured int64) int64 {
+	if configured > 0 {
+		return configured
+	}
+	return constants.DefaultMaxAttachmentBytes
+}
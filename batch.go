@@ -0,0 +1,304 @@
+package runagent
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultBatchConcurrency is used when BatchOptions.Concurrency is unset.
+const DefaultBatchConcurrency = 4
+
+// BatchOptions control RunBatch execution.
+type BatchOptions struct {
+	// Concurrency bounds the number of in-flight runs.
+	Concurrency int
+	// ItemTimeout bounds each individual run. Zero relies on the client timeout.
+	ItemTimeout time.Duration
+	// FailFast stops scheduling new items after the first failure and
+	// returns that error. Otherwise all items run and errors are collected.
+	FailFast bool
+	// Unordered returns results in completion order instead of input order.
+	Unordered bool
+	// OnProgress is called after each item completes. Calls are serialized.
+	OnProgress func(BatchProgress)
+	// CheckpointPath records completed items as JSON lines so an interrupted
+	// batch can resume; successful items found there are not re-run. Each
+	// entry carries a hash of its input, so entries for inputs that were
+	// edited or moved since are ignored and those items run again; so are
+	// items whose attachments cannot be hashed, such as plain readers. If
+	// writing the checkpoint fails, the batch still completes and RunBatch
+	// returns the results together with the write error.
+	CheckpointPath string
+}
+
+// BatchResult is the outcome of one batch item.
+type BatchResult struct {
+	Index    int
+	Output   interface{}
+	Err      error
+	Duration time.Duration
+	// Resumed is true when the result was loaded from the checkpoint.
+	Resumed bool
+}
+
+// BatchProgress reports batch completion counts.
+type BatchProgress struct {
+	Total     int
+	Completed int
+	Failed    int
+	Last      *BatchResult
+}
+
+type batchCheckpointEntry struct {
+	Index      int             `json:"index"`
+	InputHash  string          `json:"input_hash"`
+	Output     json.RawMessage `json:"output,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	ErrorType  ErrorType       `json:"error_type,omitempty"`
+	ErrorCode  string          `json:"error_code,omitempty"`
+	DurationMS int64           `json:"duration_ms"`
+}
+
+// RunBatch runs the entrypoint once per input with bounded concurrency.
+// Each input is passed to Run as a single value, so use RunInput, Kws(...),
+// a map or a struct to describe multi-argument calls.
+func (c *RunAgentClient) RunBatch(ctx context.Context, inputs []any, opts BatchOptions) ([]BatchResult, error) {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultBatchConcurrency
+	}
+
+	var hashes []string
+	if opts.CheckpointPath != "" {
+		hashes = make([]string, len(inputs))
+		for i, input := range inputs {
+			hashes[i] = batchInputHash(input)
+		}
+	}
+	done, err := loadBatchCheckpoint(opts.CheckpointPath, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	var checkpoint *os.File
+	if opts.CheckpointPath != "" {
+		checkpoint, err = os.OpenFile(opts.CheckpointPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, newError(ErrorTypeValidation, "failed to open batch checkpoint", withCause(err))
+		}
+		defer checkpoint.Close()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		results  = make([]BatchResult, 0, len(inputs))
+		progress = BatchProgress{Total: len(inputs)}
+		firstErr error
+		writeErr error
+	)
+
+	complete := func(res BatchResult) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		results = append(results, res)
+		progress.Completed++
+		if res.Err != nil {
+			progress.Failed++
+			if opts.FailFast && firstErr == nil {
+				firstErr = res.Err
+				cancel()
+			}
+		}
+		if checkpoint != nil && !res.Resumed && writeErr == nil {
+			writeErr = writeBatchCheckpoint(checkpoint, res, hashes[res.Index])
+		}
+		if opts.OnProgress != nil {
+			last := res
+			progress.Last = &last
+			opts.OnProgress(progress)
+		}
+	}
+
+	for _, res := range done {
+		complete(res)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				// Drain items handed over as a FailFast cancel landed.
+				if ctx.Err() != nil {
+					continue
+				}
+				complete(c.runBatchItem(ctx, idx, inputs[idx], opts.ItemTimeout))
+			}
+		}()
+	}
+
+schedule:
+	for idx := range inputs {
+		if _, ok := done[idx]; ok {
+			continue
+		}
+		// select picks randomly when both cases are ready, so check for
+		// cancellation first.
+		if ctx.Err() != nil {
+			break schedule
+		}
+		select {
+		case <-ctx.Done():
+			break schedule
+		case jobs <- idx:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if !opts.Unordered {
+		sort.Slice(results, func(i, j int) bool { return results[i].Index < results[j].Index })
+	}
+
+	if firstErr != nil {
+		return results, firstErr
+	}
+	if writeErr != nil {
+		return results, writeErr
+	}
+	return results, ctx.Err()
+}
+
+func (c *RunAgentClient) runBatchItem(ctx context.Context, idx int, input any, timeout time.Duration) (res BatchResult) {
+	res.Index = idx
+	start := time.Now()
+	defer func() { res.Duration = time.Since(start) }()
+
+	itemCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		itemCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	res.Output, res.Err = c.Run(itemCtx, input)
+	return res
+}
+
+// batchInputHash fingerprints an input as the arguments it is sent with, so
+// tokens such as Kws hash by content and attachments by the digest of their
+// current content. Inputs that cannot be coerced, or whose attachments
+// cannot be hashed, hash to "", which never matches a checkpoint entry.
+func batchInputHash(input any) string {
+	in, err := coerceToRunInput(input)
+	if err != nil {
+		return ""
+	}
+	args, ok := attachmentCacheValue(in.InputArgs)
+	if !ok {
+		return ""
+	}
+	kwargs, ok := attachmentCacheValue(in.InputKwargs)
+	if !ok {
+		return ""
+	}
+	data, err := json.Marshal([]interface{}{args, kwargs, in.Messages, in.State, in.ThreadID})
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// loadBatchCheckpoint returns the successful entries whose index and input
+// hash still match the inputs.
+func loadBatchCheckpoint(path string, hashes []string) (map[int]BatchResult, error) {
+	done := map[int]BatchResult{}
+	if path == "" {
+		return done, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return done, nil
+		}
+		return nil, newError(ErrorTypeValidation, "failed to read batch checkpoint", withCause(err))
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64<<20)
+	for scanner.Scan() {
+		var entry batchCheckpointEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// A torn final line from an interrupted write is expected.
+			continue
+		}
+		// Entries for inputs that were edited or moved since are ignored.
+		if entry.Index < 0 || entry.Index >= len(hashes) ||
+			entry.InputHash == "" || entry.InputHash != hashes[entry.Index] {
+			continue
+		}
+		// Failed items are retried on resume.
+		if entry.Error != "" {
+			delete(done, entry.Index)
+			continue
+		}
+		var output interface{}
+		if len(entry.Output) > 0 {
+			json.Unmarshal(entry.Output, &output)
+		}
+		done[entry.Index] = BatchResult{
+			Index:    entry.Index,
+			Output:   output,
+			Duration: time.Duration(entry.DurationMS) * time.Millisecond,
+			Resumed:  true,
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, newError(ErrorTypeValidation, "failed to read batch checkpoint", withCause(err))
+	}
+	return done, nil
+}
+
+// writeBatchCheckpoint appends the result's checkpoint entry.
+func writeBatchCheckpoint(file *os.File, res BatchResult, inputHash string) error {
+	entry := batchCheckpointEntry{
+		Index:      res.Index,
+		InputHash:  inputHash,
+		DurationMS: res.Duration.Milliseconds(),
+	}
+	if res.Err != nil {
+		entry.Error = res.Err.Error()
+		if runErr, ok := AsRunAgentError(res.Err); ok {
+			entry.ErrorType = runErr.Type
+			entry.ErrorCode = runErr.Code
+		}
+	} else if output, err := json.Marshal(res.Output); err == nil {
+		entry.Output = output
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return newError(ErrorTypeValidation, "failed to serialize batch checkpoint entry", withCause(err))
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return newError(ErrorTypeValidation, "failed to write batch checkpoint", withCause(err))
+	}
+	return nil
+}
@@ -0,0 +1,327 @@
+package runagent
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBenchmarkDuration    = 30 * time.Second
+	defaultBenchmarkConcurrency = 1
+)
+
+// BenchmarkConfig controls a load test run by Benchmark.
+type BenchmarkConfig struct {
+	// Duration is how long new requests are started; in-flight requests are
+	// allowed to finish. Defaults to 30s.
+	Duration time.Duration
+	// Concurrency is the number of requests in flight at once; defaults to 1.
+	// Without RPS each worker sends its next request as soon as the previous
+	// one completes.
+	Concurrency int
+	// RPS paces request starts at a fixed rate. Latency is then measured from
+	// each request's scheduled start, so time spent waiting for a free worker
+	// counts against the deployment rather than being hidden.
+	RPS float64
+	// Input holds the values passed to Run or RunStream for every request.
+	Input []any
+	// OnResult is called after each request completes. Calls are serialized.
+	OnResult func(BenchmarkSample)
+}
+
+// BenchmarkSample describes one completed benchmark request.
+type BenchmarkSample struct {
+	Latency    time.Duration
+	FirstChunk time.Duration
+	Err        error
+}
+
+// LatencySummary reports latency statistics in milliseconds.
+type LatencySummary struct {
+	Count int64   `json:"count"`
+	Min   float64 `json:"min_ms"`
+	Mean  float64 `json:"mean_ms"`
+	P50   float64 `json:"p50_ms"`
+	P90   float64 `json:"p90_ms"`
+	P95   float64 `json:"p95_ms"`
+	P99   float64 `json:"p99_ms"`
+	P999  float64 `json:"p999_ms"`
+	Max   float64 `json:"max_ms"`
+}
+
+// BenchmarkError counts failures sharing an error type and code.
+type BenchmarkError struct {
+	Type    ErrorType `json:"type"`
+	Code    string    `json:"code,omitempty"`
+	Count   int64     `json:"count"`
+	Example string    `json:"example"`
+}
+
+// BenchmarkResult is the outcome of Benchmark. It marshals to JSON; the
+// histograms are exported separately with WritePercentileDistribution.
+type BenchmarkResult struct {
+	AgentID       string           `json:"agent_id"`
+	EntrypointTag string           `json:"entrypoint_tag"`
+	Stream        bool             `json:"stream"`
+	Concurrency   int              `json:"concurrency"`
+	TargetRPS     float64          `json:"target_rps,omitempty"`
+	Requests      int64            `json:"requests"`
+	Successes     int64            `json:"successes"`
+	Failures      int64            `json:"failures"`
+	Elapsed       float64          `json:"elapsed_seconds"`
+	Throughput    float64          `json:"throughput_rps"`
+	Latency       LatencySummary   `json:"latency"`
+	FirstChunk    *LatencySummary  `json:"first_chunk,omitempty"`
+	Errors        []BenchmarkError `json:"errors"`
+
+	// LatencyHistogram covers successful requests.
+	LatencyHistogram *Histogram `json:"-"`
+	// FirstChunkHistogram covers streams that produced at least one chunk.
+	FirstChunkHistogram *Histogram `json:"-"`
+}
+
+// Benchmark drives the client's entrypoint under load and reports latency
+// percentiles, throughput and failures grouped by ErrorType and Code. Stream
+// entrypoints (*_stream tags) are driven through RunStream and also report
+// time to first chunk. The response cache is bypassed so every request
+// reaches the agent. The client's rate limiter and circuit breaker are
+// bypassed too: the benchmark measures the agent rather than client-side
+// throttling, and its failures neither trip nor are blocked by the breaker
+// that other clients of the same agent share. Cancelling ctx stops the run
+// early.
+func Benchmark(ctx context.Context, client *RunAgentClient, cfg BenchmarkConfig) (*BenchmarkResult, error) {
+	if client == nil {
+		return nil, newError(ErrorTypeValidation, "benchmark requires a client")
+	}
+	if cfg.RPS < 0 || cfg.Concurrency < 0 || cfg.Duration < 0 {
+		return nil, newError(ErrorTypeValidation, "benchmark duration, concurrency and rps must not be negative")
+	}
+	if cfg.Duration == 0 {
+		cfg.Duration = defaultBenchmarkDuration
+	}
+	if cfg.Concurrency == 0 {
+		cfg.Concurrency = defaultBenchmarkConcurrency
+	}
+
+	// Drive load through a copy without the shared limiter and breaker.
+	bench := *client
+	bench.rateLimiter = nil
+	bench.breaker = nil
+	client = &bench
+
+	stream := isStreamTag(client.entrypointTag)
+	values := append(append([]any{}, cfg.Input...), BypassCache())
+	// Validate the input once up front rather than failing every request.
+	if _, err := coerceToRunInput(values...); err != nil {
+		return nil, err
+	}
+
+	rec := &benchmarkRecorder{
+		latency:    NewHistogram(),
+		firstChunk: NewHistogram(),
+		errors:     map[benchmarkErrorKey]*BenchmarkError{},
+		onResult:   cfg.OnResult,
+	}
+	do := func(scheduled time.Time) {
+		sample := BenchmarkSample{}
+		if stream {
+			sample.FirstChunk, sample.Err = benchmarkStream(ctx, client, values, scheduled)
+		} else {
+			_, sample.Err = client.RunWithResult(ctx, values...)
+		}
+		sample.Latency = time.Since(scheduled)
+		rec.record(sample)
+	}
+
+	started := time.Now()
+	deadline := started.Add(cfg.Duration)
+	var wg sync.WaitGroup
+	if cfg.RPS > 0 {
+		runPaced(ctx, deadline, cfg.RPS, cfg.Concurrency, do, &wg)
+	} else {
+		runClosedLoop(ctx, deadline, cfg.Concurrency, do, &wg)
+	}
+	wg.Wait()
+	elapsed := time.Since(started)
+
+	result := rec.result(elapsed)
+	result.AgentID = client.agentID
+	result.EntrypointTag = client.entrypointTag
+	result.Stream = stream
+	result.Concurrency = cfg.Concurrency
+	result.TargetRPS = cfg.RPS
+	if !stream {
+		result.FirstChunk = nil
+		result.FirstChunkHistogram = nil
+	}
+	return result, nil
+}
+
+// runClosedLoop starts concurrency workers that each send requests back to
+// back until the deadline.
+func runClosedLoop(ctx context.Context, deadline time.Time, concurrency int, do func(time.Time), wg *sync.WaitGroup) {
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil && time.Now().Before(deadline) {
+				do(time.Now())
+			}
+		}()
+	}
+}
+
+// runPaced starts requests on a fixed schedule, with at most concurrency in
+// flight. Starts delayed by a full pool keep their scheduled time.
+func runPaced(ctx context.Context, deadline time.Time, rps float64, concurrency int, do func(time.Time), wg *sync.WaitGroup) {
+	interval := time.Duration(float64(time.Second) / rps)
+	slots := make(chan struct{}, concurrency)
+	next := time.Now()
+	for next.Before(deadline) {
+		if wait := time.Until(next); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case slots <- struct{}{}:
+		}
+
+		scheduled := next
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			do(scheduled)
+		}()
+		next = next.Add(interval)
+	}
+}
+
+// benchmarkStream consumes a stream to completion and returns the time from
+// the scheduled start to the first data chunk.
+func benchmarkStream(ctx context.Context, client *RunAgentClient, values []any, scheduled time.Time) (time.Duration, error) {
+	iter, err := client.RunStream(ctx, values...)
+	if err != nil {
+		return 0, err
+	}
+	defer iter.Close()
+
+	var firstChunk time.Duration
+	for {
+		event, more, err := iter.NextEvent(ctx)
+		if event != nil && event.Kind == StreamEventData && firstChunk == 0 {
+			firstChunk = time.Since(scheduled)
+		}
+		if err != nil {
+			return firstChunk, err
+		}
+		if !more {
+			return firstChunk, nil
+		}
+	}
+}
+
+type benchmarkErrorKey struct {
+	kind ErrorType
+	code string
+}
+
+type benchmarkRecorder struct {
+	mu         sync.Mutex
+	requests   int64
+	failures   int64
+	latency    *Histogram
+	firstChunk *Histogram
+	errors     map[benchmarkErrorKey]*BenchmarkError
+	onResult   func(BenchmarkSample)
+}
+
+func (r *benchmarkRecorder) record(sample BenchmarkSample) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.requests++
+	if sample.FirstChunk > 0 {
+		r.firstChunk.Record(sample.FirstChunk)
+	}
+	if sample.Err == nil {
+		r.latency.Record(sample.Latency)
+	} else {
+		r.failures++
+		key := benchmarkErrorKey{kind: ErrorTypeUnknown}
+		if runErr, ok := AsRunAgentError(sample.Err); ok {
+			key = benchmarkErrorKey{kind: runErr.Type, code: runErr.Code}
+		} else if errors.Is(sample.Err, context.Canceled) || errors.Is(sample.Err, context.DeadlineExceeded) {
+			key.code = "CANCELLED"
+		}
+		entry, ok := r.errors[key]
+		if !ok {
+			entry = &BenchmarkError{Type: key.kind, Code: key.code, Example: sample.Err.Error()}
+			r.errors[key] = entry
+		}
+		entry.Count++
+	}
+	if r.onResult != nil {
+		r.onResult(sample)
+	}
+}
+
+func (r *benchmarkRecorder) result(elapsed time.Duration) *BenchmarkResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := &BenchmarkResult{
+		Requests:            r.requests,
+		Successes:           r.requests - r.failures,
+		Failures:            r.failures,
+		Elapsed:             elapsed.Seconds(),
+		Latency:             summarizeLatency(r.latency),
+		LatencyHistogram:    r.latency,
+		FirstChunkHistogram: r.firstChunk,
+		Errors:              make([]BenchmarkError, 0, len(r.errors)),
+	}
+	if elapsed > 0 {
+		result.Throughput = float64(result.Successes) / elapsed.Seconds()
+	}
+	firstChunk := summarizeLatency(r.firstChunk)
+	result.FirstChunk = &firstChunk
+
+	for _, entry := range r.errors {
+		result.Errors = append(result.Errors, *entry)
+	}
+	sort.Slice(result.Errors, func(i, j int) bool {
+		if result.Errors[i].Count != result.Errors[j].Count {
+			return result.Errors[i].Count > result.Errors[j].Count
+		}
+		if result.Errors[i].Type != result.Errors[j].Type {
+			return result.Errors[i].Type < result.Errors[j].Type
+		}
+		return result.Errors[i].Code < result.Errors[j].Code
+	})
+	return result
+}
+
+func summarizeLatency(h *Histogram) LatencySummary {
+	ms := func(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
+	return LatencySummary{
+		Count: h.TotalCount(),
+		Min:   ms(h.Min()),
+		Mean:  ms(h.Mean()),
+		P50:   ms(h.ValueAtPercentile(50)),
+		P90:   ms(h.ValueAtPercentile(90)),
+		P95:   ms(h.ValueAtPercentile(95)),
+		P99:   ms(h.ValueAtPercentile(99)),
+		P999:  ms(h.ValueAtPercentile(99.9)),
+		Max:   ms(h.Max()),
+	}
+}
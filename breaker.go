@@ -0,0 +1,207 @@
+package runagent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of an agent's circuit breaker.
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"
+	CircuitOpen     CircuitState = "open"
+	CircuitHalfOpen CircuitState = "half_open"
+)
+
+const (
+	defaultBreakerFailureThreshold = 5
+	defaultBreakerCoolDown         = 30 * time.Second
+)
+
+// CircuitBreakerConfig enables fail-fast behavior for an unhealthy agent.
+// Breakers are keyed by agent ID and shared by all clients in the process.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures that opens the
+	// circuit; defaults to 5.
+	FailureThreshold int
+	// CoolDown is how long the circuit stays open before allowing a trial
+	// request; defaults to 30s.
+	CoolDown time.Duration
+	// HalfOpenMaxRequests bounds concurrent trial requests; defaults to 1.
+	HalfOpenMaxRequests int
+	// OnStateChange is invoked after every transition.
+	OnStateChange func(agentID string, from, to CircuitState)
+}
+
+var sharedBreakers = struct {
+	mu sync.Mutex
+	m  map[string]*circuitBreaker
+}{m: map[string]*circuitBreaker{}}
+
+type circuitBreaker struct {
+	agentID string
+
+	mu       sync.Mutex
+	cfg      CircuitBreakerConfig
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probes   int
+}
+
+// sharedCircuitBreaker returns the process-wide breaker for the agent,
+// applying the latest configuration.
+func sharedCircuitBreaker(agentID string, cfg *CircuitBreakerConfig) *circuitBreaker {
+	if cfg == nil {
+		return nil
+	}
+
+	resolved := *cfg
+	if resolved.FailureThreshold <= 0 {
+		resolved.FailureThreshold = defaultBreakerFailureThreshold
+	}
+	if resolved.CoolDown <= 0 {
+		resolved.CoolDown = defaultBreakerCoolDown
+	}
+	if resolved.HalfOpenMaxRequests <= 0 {
+		resolved.HalfOpenMaxRequests = 1
+	}
+
+	sharedBreakers.mu.Lock()
+	defer sharedBreakers.mu.Unlock()
+
+	breaker, ok := sharedBreakers.m[agentID]
+	if !ok {
+		breaker = &circuitBreaker{agentID: agentID, state: CircuitClosed}
+		sharedBreakers.m[agentID] = breaker
+	}
+	breaker.mu.Lock()
+	breaker.cfg = resolved
+	breaker.mu.Unlock()
+	return breaker
+}
+
+// CircuitState reports the circuit breaker state for this client's agent.
+// Clients without Config.CircuitBreaker are always closed.
+func (c *RunAgentClient) CircuitState() CircuitState {
+	if c.breaker == nil {
+		return CircuitClosed
+	}
+	c.breaker.mu.Lock()
+	defer c.breaker.mu.Unlock()
+	return c.breaker.currentStateLocked()
+}
+
+// allow admits a request or fails fast with CIRCUIT_OPEN.
+func (b *circuitBreaker) allow() error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	from := b.state
+	state := b.currentStateLocked()
+	var err error
+	switch state {
+	case CircuitOpen:
+		err = b.openErrorLocked()
+	case CircuitHalfOpen:
+		if b.probes >= b.cfg.HalfOpenMaxRequests {
+			err = b.openErrorLocked()
+		} else {
+			b.probes++
+		}
+	}
+	b.state = state
+	cb := b.cfg.OnStateChange
+	b.mu.Unlock()
+
+	if from != state && cb != nil {
+		cb(b.agentID, from, state)
+	}
+	return err
+}
+
+// record feeds the outcome of an admitted request into the breaker.
+func (b *circuitBreaker) record(err error) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	from := b.state
+	if from == CircuitHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		// Caller gave up; says nothing about agent health.
+	case isBreakerFailure(err):
+		b.failures++
+		if from == CircuitHalfOpen || b.failures >= b.cfg.FailureThreshold {
+			b.state = CircuitOpen
+			b.openedAt = time.Now()
+			b.probes = 0
+		}
+	default:
+		b.failures = 0
+		b.state = CircuitClosed
+		b.probes = 0
+	}
+	to := b.state
+	cb := b.cfg.OnStateChange
+	b.mu.Unlock()
+
+	if from != to && cb != nil {
+		cb(b.agentID, from, to)
+	}
+}
+
+func (b *circuitBreaker) currentStateLocked() CircuitState {
+	if b.state == CircuitOpen && time.Since(b.openedAt) >= b.cfg.CoolDown {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+func (b *circuitBreaker) openErrorLocked() *RunAgentError {
+	retryIn := b.cfg.CoolDown - time.Since(b.openedAt)
+	if retryIn < 0 {
+		retryIn = 0
+	}
+	return newError(
+		ErrorTypeConnection,
+		fmt.Sprintf("circuit open for agent %s", b.agentID),
+		withCode("CIRCUIT_OPEN"),
+		withDetails(map[string]interface{}{
+			"agent_id":         b.agentID,
+			"retry_in_seconds": retryIn.Seconds(),
+		}),
+		withSuggestion("The agent is failing repeatedly; retry after the cool-down or check its deployment"),
+	)
+}
+
+// isBreakerFailure reports whether err indicates an unhealthy deployment:
+// connection failures, timeouts and 5xx responses. Validation,
+// authentication and agent-level execution errors do not count.
+func isBreakerFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var execErr *RunAgentExecutionError
+	if errors.As(err, &execErr) {
+		return execErr.HTTPStatus >= 500
+	}
+	if runErr, ok := AsRunAgentError(err); ok {
+		return runErr.Type == ErrorTypeConnection
+	}
+	return false
+}
@@ -0,0 +1,369 @@
+package runagent
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/runagent-dev/runagent-go/internal/db"
+)
+
+// DefaultCacheEntries bounds the in-memory cache used when CacheConfig.Backend is unset.
+const DefaultCacheEntries = 1024
+
+// Cache is a byte-oriented store for cached responses. Implementations must
+// be safe for concurrent use; a zero ttl means the entry never expires.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// CacheConfig enables response caching for deterministic entrypoints.
+// Runs are keyed by agent ID, entrypoint tag and a hash of the base URL and
+// the request.
+type CacheConfig struct {
+	// Backend stores entries; defaults to NewMemoryCache(DefaultCacheEntries).
+	Backend Cache
+	// TTL bounds successful run results. Zero never expires.
+	TTL time.Duration
+	// ArchitectureTTL bounds GetArchitecture results; defaults to TTL.
+	ArchitectureTTL time.Duration
+	// NegativeTTL enables caching of deterministic failures for this long.
+	// Zero disables negative caching.
+	NegativeTTL time.Duration
+	// NegativeRule decides which failures are cached when NegativeTTL is set.
+	// The default caches HTTP 400, 404 and 422. Agent-reported failures
+	// (HTTP 200) are often transient upstream errors, so opt in explicitly.
+	NegativeRule func(status int, err error) bool
+}
+
+// responseCache applies a CacheConfig on behalf of one client.
+type responseCache struct {
+	cfg CacheConfig
+}
+
+type cachedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body"`
+}
+
+func newResponseCache(cfg *CacheConfig) *responseCache {
+	if cfg == nil {
+		return nil
+	}
+	resolved := *cfg
+	if resolved.Backend == nil {
+		resolved.Backend = NewMemoryCache(DefaultCacheEntries)
+	}
+	if resolved.ArchitectureTTL <= 0 {
+		resolved.ArchitectureTTL = resolved.TTL
+	}
+	if resolved.NegativeRule == nil {
+		resolved.NegativeRule = defaultNegativeCacheRule
+	}
+	return &responseCache{cfg: resolved}
+}
+
+// runCacheKey derives the cache key for a run before its attachments are
+// uploaded, so a hit needs no upload. Attachments are keyed by content
+// digest and maps are encoded with sorted keys, so equal requests hash
+// identically. The base URL is part of the key, keeping deployments that
+// share an agent ID apart. It returns "" for runs that cannot be cached:
+// thread runs, whose reply depends on the thread's server-side state,
+// async submissions, which return a handle rather than a result, and
+// attachments read from a stream without a SHA256.
+func (c *RunAgentClient) runCacheKey(input RunInput) string {
+	if c.cache == nil || input.ThreadID != "" {
+		return ""
+	}
+	keyed := input
+	keyed.InputArgs = make([]interface{}, len(input.InputArgs))
+	for i, arg := range input.InputArgs {
+		value, ok := attachmentCacheValue(arg)
+		if !ok {
+			return ""
+		}
+		keyed.InputArgs[i] = value
+	}
+	keyed.InputKwargs = make(map[string]interface{}, len(input.InputKwargs))
+	for k, v := range input.InputKwargs {
+		value, ok := attachmentCacheValue(v)
+		if !ok {
+			return ""
+		}
+		keyed.InputKwargs[k] = value
+	}
+
+	payload := keyed.toAPIPayload(c.entrypointTag, c.timeoutSecs, c.asyncDefault)
+	if payload.AsyncExecution {
+		return ""
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(append([]byte(c.baseRESTURL+"\n"), body...))
+	return fmt.Sprintf("runagent:run:%s:%s:%s", c.agentID, c.entrypointTag, hex.EncodeToString(sum[:]))
+}
+
+func (c *RunAgentClient) architectureCacheKey() string {
+	sum := sha256.Sum256([]byte(c.baseRESTURL))
+	return fmt.Sprintf("runagent:architecture:%s:%s", c.agentID, hex.EncodeToString(sum[:8]))
+}
+
+// lookup returns a cached response. Backend errors are treated as misses.
+func (rc *responseCache) lookup(ctx context.Context, key string) (*cachedResponse, bool) {
+	if rc == nil || key == "" {
+		return nil, false
+	}
+	raw, ok, err := rc.cfg.Backend.Get(ctx, key)
+	if err != nil || !ok {
+		return nil, false
+	}
+	var entry cachedResponse
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return nil, false
+	}
+	return &entry, true
+}
+
+// store caches a run response according to the TTL and negative rules.
+func (rc *responseCache) store(ctx context.Context, key string, entry cachedResponse, runErr error) {
+	if rc == nil || key == "" {
+		return
+	}
+	ttl := rc.cfg.TTL
+	if runErr != nil {
+		if rc.cfg.NegativeTTL <= 0 || !rc.cfg.NegativeRule(entry.Status, runErr) {
+			return
+		}
+		ttl = rc.cfg.NegativeTTL
+	}
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	rc.cfg.Backend.Set(ctx, key, raw, ttl)
+}
+
+// defaultNegativeCacheRule caches failures that repeating the same request
+// cannot fix. Timeouts, rate limits, auth and 5xx errors are never cached.
+func defaultNegativeCacheRule(status int, err error) bool {
+	switch status {
+	case http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity:
+		return true
+	default:
+		return false
+	}
+}
+
+// MemoryCache is an in-process LRU cache.
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List
+	items      map[string]*list.Element
+}
+
+type memoryCacheItem struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewMemoryCache creates an LRU cache holding at most maxEntries entries.
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultCacheEntries
+	}
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		items:      map[string]*list.Element{},
+	}
+}
+
+// Get implements Cache.
+func (m *MemoryCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	elem, ok := m.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	item := elem.Value.(*memoryCacheItem)
+	if !item.expiresAt.IsZero() && time.Now().After(item.expiresAt) {
+		m.order.Remove(elem)
+		delete(m.items, key)
+		return nil, false, nil
+	}
+	m.order.MoveToFront(elem)
+	return item.value, true, nil
+}
+
+// Set implements Cache.
+func (m *MemoryCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item := &memoryCacheItem{key: key, value: value}
+	if ttl > 0 {
+		item.expiresAt = time.Now().Add(ttl)
+	}
+	if elem, ok := m.items[key]; ok {
+		elem.Value = item
+		m.order.MoveToFront(elem)
+		return nil
+	}
+	m.items[key] = m.order.PushFront(item)
+	for m.order.Len() > m.maxEntries {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.items, oldest.Value.(*memoryCacheItem).key)
+	}
+	return nil
+}
+
+// Delete implements Cache.
+func (m *MemoryCache) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if elem, ok := m.items[key]; ok {
+		m.order.Remove(elem)
+		delete(m.items, key)
+	}
+	return nil
+}
+
+// SQLiteCache stores entries in the local RunAgent SQLite database.
+type SQLiteCache struct {
+	svc *db.Service
+}
+
+// NewSQLiteCache opens the cache table in the database at dbPath. An empty
+// path uses the default RunAgent database.
+func NewSQLiteCache(dbPath string) (*SQLiteCache, error) {
+	svc, err := db.NewService(dbPath)
+	if err != nil {
+		return nil, newError(ErrorTypeValidation, "failed to open cache database", withCause(err))
+	}
+	return &SQLiteCache{svc: svc}, nil
+}
+
+// Get implements Cache.
+func (s *SQLiteCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	return s.svc.GetCacheEntry(key)
+}
+
+// Set implements Cache.
+func (s *SQLiteCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.svc.SetCacheEntry(key, value, ttl)
+}
+
+// Delete implements Cache.
+func (s *SQLiteCache) Delete(_ context.Context, key string) error {
+	return s.svc.DeleteCacheEntry(key)
+}
+
+// Purge removes expired entries.
+func (s *SQLiteCache) Purge() error {
+	_, err := s.svc.PurgeExpiredCacheEntries()
+	return err
+}
+
+// Close closes the underlying database.
+func (s *SQLiteCache) Close() error {
+	return s.svc.Close()
+}
+
+// FileCache stores one file per entry in a directory.
+type FileCache struct {
+	dir string
+}
+
+type fileCacheEntry struct {
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+	Value     []byte    `json:"value"`
+}
+
+// NewFileCache creates the cache directory if needed.
+func NewFileCache(dir string) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, newError(ErrorTypeValidation, "failed to create cache directory", withCause(err))
+	}
+	return &FileCache{dir: dir}, nil
+}
+
+func (f *FileCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(f.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Get implements Cache.
+func (f *FileCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	raw, err := os.ReadFile(f.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	var entry fileCacheEntry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return nil, false, nil
+	}
+	if !entry.ExpiresAt.IsZero() && time.Now().After(entry.ExpiresAt) {
+		os.Remove(f.path(key))
+		return nil, false, nil
+	}
+	return entry.Value, true, nil
+}
+
+// Set implements Cache. Entries are written to a temporary file and renamed
+// so concurrent readers never see partial writes.
+func (f *FileCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	entry := fileCacheEntry{Value: value}
+	if ttl > 0 {
+		entry.ExpiresAt = time.Now().Add(ttl)
+	}
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(f.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path(key))
+}
+
+// Delete implements Cache.
+func (f *FileCache) Delete(_ context.Context, key string) error {
+	err := os.Remove(f.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
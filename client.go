@@ -16,8 +16,10 @@ import (
 
 	"github.com/gorilla/websocket"
 
+	"github.com/runagent-dev/runagent-go/internal/config"
 	"github.com/runagent-dev/runagent-go/internal/constants"
 	"github.com/runagent-dev/runagent-go/internal/db"
+	"github.com/runagent-dev/runagent-go/internal/utils"
 )
 
 // RunAgentClient is the main entry point for invoking RunAgent deployments.
@@ -27,11 +29,21 @@ type RunAgentClient struct {
 	local         bool
 	baseRESTURL   string
 	baseSocketURL string
-	apiKey        string
+	credentials   CredentialProvider
 	timeoutSecs   int
 	asyncDefault  bool
 	extraParams   map[string]interface{}
 	httpClient    *http.Client
+	wsDialer      *websocket.Dialer
+
+	streamTransports   []StreamTransport
+	maxAttachmentBytes int64
+	rateLimiter        *rateLimiter
+	breaker            *circuitBreaker
+	cache              *responseCache
+	validator          *inputValidator
+	agentConfig        *AgentConfig
+	adapter            FrameworkAdapter
 }
 
 // NewRunAgentClient creates a new client instance using the provided config.
@@ -43,65 +55,54 @@ func NewRunAgentClient(cfg Config) (*RunAgentClient, error) {
 		return nil, newError(ErrorTypeValidation, "entrypoint_tag is required")
 	}
 
-	env := loadEnvConfig()
-
-	local := resolveBool(cfg.Local, env.local, false)
-	asyncDefault := resolveBool(cfg.AsyncExecution, nil, false)
-
-	timeout := cfg.TimeoutSeconds
-	if timeout <= 0 {
-		timeout = env.timeoutSeconds
-	}
-	if timeout <= 0 {
-		timeout = constants.DefaultTimeoutSeconds
+	resolved, err := resolveConfig(cfg)
+	if err != nil {
+		return nil, err
 	}
-
-	apiKey := firstNonEmpty(cfg.APIKey, env.apiKey)
-	baseURL := firstNonEmpty(cfg.BaseURL, env.baseURL, constants.DefaultBaseURL)
+	asyncDefault := resolveBool(cfg.AsyncExecution, nil, false)
 
 	var restBase, socketBase string
-	var host string
-	var port int
-	if local {
-		host = firstNonEmpty(cfg.Host, env.host)
-		port = firstNonZero(cfg.Port, env.port)
-
-		if host == "" || port == 0 {
-			discoveredHost, discoveredPort, err := discoverLocalAgent(cfg.AgentID)
+	if resolved.local {
+		if resolved.needsRegistry() {
+			agent, err := discoverLocalAgent(cfg.AgentID)
 			if err != nil {
 				return nil, err
 			}
-			if host == "" {
-				host = discoveredHost
-			}
-			if port == 0 {
-				port = discoveredPort
-			}
+			resolved.applyRegistry(agent)
 		}
 
-		if host == "" || port == 0 {
-			return nil, newError(
-				ErrorTypeValidation,
-				"unable to resolve local host/port",
-				withSuggestion("Pass Config.Host/Config.Port or ensure the agent is registered locally"),
-			)
-		}
+		if resolved.socketPath != "" {
+			// The host is a placeholder; every connection dials the socket.
+			restBase = fmt.Sprintf("http://localhost%s", constants.DefaultAPIPrefix)
+			socketBase = fmt.Sprintf("ws://localhost%s", constants.DefaultAPIPrefix)
+		} else {
+			if resolved.host == "" || resolved.port == 0 {
+				return nil, newError(
+					ErrorTypeValidation,
+					"unable to resolve local host/port",
+					withSuggestion("Pass Config.Host/Config.Port or Config.SocketPath, or ensure the agent is registered locally"),
+				)
+			}
 
-		restBase = fmt.Sprintf("http://%s:%d%s", host, port, constants.DefaultAPIPrefix)
-		socketBase = fmt.Sprintf("ws://%s:%d%s", host, port, constants.DefaultAPIPrefix)
+			restBase = fmt.Sprintf("http://%s:%d%s", resolved.host, resolved.port, constants.DefaultAPIPrefix)
+			socketBase = fmt.Sprintf("ws://%s:%d%s", resolved.host, resolved.port, constants.DefaultAPIPrefix)
+		}
 	} else {
 		var err error
-		restBase, socketBase, err = normalizeRemoteBases(baseURL)
+		restBase, socketBase, err = normalizeRemoteBases(resolved.baseURL)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	httpClient := cfg.HTTPClient
-	if httpClient == nil {
-		httpClient = &http.Client{
-			Timeout: time.Duration(timeout) * time.Second,
-		}
+	httpClient, err := buildHTTPClient(cfg.HTTPClient, time.Duration(resolved.timeout)*time.Second, resolved.tls, cfg.Proxy, resolved.socketPath)
+	if err != nil {
+		return nil, err
+	}
+
+	transports, err := resolveStreamTransports(StreamTransport(resolved.transport))
+	if err != nil {
+		return nil, err
 	}
 
 	extra := cfg.ExtraParams
@@ -109,17 +110,32 @@ func NewRunAgentClient(cfg Config) (*RunAgentClient, error) {
 		extra = map[string]interface{}{}
 	}
 
+	validator, err := newInputValidator(cfg.Validation, cfg.EntrypointTag)
+	if err != nil {
+		return nil, err
+	}
+
 	return &RunAgentClient{
 		agentID:       cfg.AgentID,
 		entrypointTag: cfg.EntrypointTag,
-		local:         local,
+		local:         resolved.local,
 		baseRESTURL:   restBase,
 		baseSocketURL: socketBase,
-		apiKey:        apiKey,
-		timeoutSecs:   timeout,
+		credentials:   resolved.credentials,
+		timeoutSecs:   resolved.timeout,
 		asyncDefault:  asyncDefault,
 		extraParams:   extra,
 		httpClient:    httpClient,
+		wsDialer:      newWebSocketDialer(httpClient),
+
+		streamTransports:   transports,
+		maxAttachmentBytes: resolveMaxAttachmentBytes(cfg.MaxAttachmentBytes),
+		rateLimiter:        sharedRateLimiter(cfg.AgentID, cfg.EntrypointTag, cfg.RateLimit),
+		breaker:            sharedCircuitBreaker(cfg.AgentID, cfg.CircuitBreaker),
+		cache:              newResponseCache(cfg.Cache),
+		validator:          validator,
+		agentConfig:        resolved.agentConfig,
+		adapter:            AdapterFor(resolved.framework),
 	}, nil
 }
 
@@ -131,8 +147,20 @@ func NewRunAgentClient(cfg Config) (*RunAgentClient, error) {
 //  - struct:     Run(ctx, MyStruct{...}) -> kwargs via json tags
 //  - single:     Run(ctx, "hello") -> ["hello"], {}
 func (c *RunAgentClient) Run(ctx context.Context, values ...any) (interface{}, error) {
+	result, err := c.RunWithResult(ctx, values...)
+	if err != nil {
+		return nil, err
+	}
+	return result.Output, nil
+}
+
+// RunWithResult behaves like Run but also returns the response metadata:
+// request and run IDs, the raw envelope, HTTP status and headers, server and
+// client timing, and token usage when the framework reports it.
+// Pass IdempotencyKey("...") among the values to make retries safe.
+func (c *RunAgentClient) RunWithResult(ctx context.Context, values ...any) (result *RunResult, err error) {
 	// Guardrail: non-stream only
-	if c.entrypointTag == "generic_stream" || c.entrypointTag == "stream" || strings.HasSuffix(strings.ToLower(c.entrypointTag), "_stream") {
+	if isStreamTag(c.entrypointTag) {
 		return nil, newError(
 			ErrorTypeValidation,
 			"stream entrypoint must be invoked with RunStream",
@@ -141,10 +169,39 @@ func (c *RunAgentClient) Run(ctx context.Context, values ...any) (interface{}, e
 		)
 	}
 
-	input, err := coerceToRunInput(values...)
+	input, err := c.prepareInput(ctx, values...)
 	if err != nil {
 		return nil, err
 	}
+
+	meta := &RunResult{
+		RequestID:      utils.NewUUID(),
+		IdempotencyKey: input.IdempotencyKey,
+	}
+	// Tag every error leaving this point with the IDs we know about.
+	defer func() {
+		if err != nil {
+			err = attachRequestIDs(err, meta.RequestID, meta.RunID)
+		}
+	}()
+
+	// Look the run up before uploading attachments so a hit skips the upload.
+	cacheKey := c.runCacheKey(input)
+	if !input.BypassCache {
+		if entry, ok := c.cache.lookup(ctx, cacheKey); ok {
+			meta.Cached = true
+			meta.captureCached(entry)
+			meta.Output, err = decodeRunResponse(entry.Status, entry.Header, entry.Body)
+			if err != nil {
+				return nil, err
+			}
+			return meta, nil
+		}
+	}
+
+	if err := c.resolveAttachments(ctx, &input); err != nil {
+		return nil, err
+	}
 	payload := input.toAPIPayload(c.entrypointTag, c.timeoutSecs, c.asyncDefault)
 
 	body, err := json.Marshal(payload)
@@ -160,18 +217,24 @@ func (c *RunAgentClient) Run(ctx context.Context, values ...any) (interface{}, e
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", userAgent())
-	if !c.local {
-		if c.apiKey == "" {
-			return nil, newError(
-				ErrorTypeAuthentication,
-				"api_key is required for remote runs",
-				withSuggestion("Set RUNAGENT_API_KEY or pass Config.APIKey"),
-			)
-		}
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
+	setRequestIDHeaders(req.Header, meta.RequestID, meta.IdempotencyKey)
+	if err := c.applyAuth(req); err != nil {
+		return nil, err
+	}
+
+	release, err := c.rateLimiter.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if err := c.breaker.allow(); err != nil {
+		return nil, err
 	}
+	defer func() { c.breaker.record(err) }()
 
-	resp, err := c.httpClient.Do(req)
+	sentAt := time.Now()
+	resp, err := c.doAuthorized(c.httpClient, req)
 	if err != nil {
 		return nil, newError(
 			ErrorTypeConnection,
@@ -181,17 +244,33 @@ func (c *RunAgentClient) Run(ctx context.Context, values ...any) (interface{}, e
 		)
 	}
 	defer resp.Body.Close()
+	c.rateLimiter.observe(resp)
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, newError(ErrorTypeUnknown, "failed to read response body", withCause(err))
 	}
+	meta.captureResponse(resp, respBody, time.Since(sentAt))
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, translateHTTPError(resp.StatusCode, respBody)
+	output, err := decodeRunResponse(resp.StatusCode, resp.Header, respBody)
+	c.cache.store(ctx, cacheKey, cachedResponse{
+		Status: resp.StatusCode,
+		Header: resp.Header,
+		Body:   respBody,
+	}, err)
+	if err != nil {
+		return nil, err
 	}
+	meta.Output = output
+	return meta, nil
+}
 
-	return parseRunResponse(resp.StatusCode, respBody)
+// decodeRunResponse turns a run response into the output or the matching error.
+func decodeRunResponse(status int, header http.Header, body []byte) (interface{}, error) {
+	if status != http.StatusOK {
+		return nil, translateHTTPError(status, header, body)
+	}
+	return parseRunResponse(status, body)
 }
 
 // RunNative invokes the agent using native Go-shaped arguments without requiring RunInput.
@@ -212,7 +291,7 @@ func (c *RunAgentClient) RunNative(ctx context.Context, values ...any) (interfac
 // RunStream starts a streaming execution via WebSocket using native arguments.
 func (c *RunAgentClient) RunStream(ctx context.Context, values ...any) (*StreamIterator, error) {
 	// Guardrail: stream only
-	if !(c.entrypointTag == "generic_stream" || c.entrypointTag == "stream" || strings.HasSuffix(strings.ToLower(c.entrypointTag), "_stream")) {
+	if !isStreamTag(c.entrypointTag) {
 		return nil, newError(
 			ErrorTypeValidation,
 			"non-stream entrypoint must be invoked with Run",
@@ -221,50 +300,58 @@ func (c *RunAgentClient) RunStream(ctx context.Context, values ...any) (*StreamI
 		)
 	}
 
-	input, err := coerceToRunInput(values...)
+	input, err := c.prepareInput(ctx, values...)
 	if err != nil {
 		return nil, err
 	}
+	if err := c.resolveAttachments(ctx, &input); err != nil {
+		return nil, err
+	}
 
 	// Optional final StreamOptions can be passed via Kw("__timeout_seconds__", x)
 	// but we keep defaults; consider functional options in future.
 	timeout := constants.DefaultStreamTimeout
 	payload := input.toAPIPayload(c.entrypointTag, timeout, false)
 	payload.AsyncExecution = false
+	payload.RequestID = utils.NewUUID()
+	payload.IdempotencyKey = input.IdempotencyKey
 
-	data, err := json.Marshal(payload)
+	release, err := c.rateLimiter.acquire(ctx)
 	if err != nil {
-		return nil, newError(ErrorTypeValidation, "failed to serialize stream payload", withCause(err))
+		return nil, err
 	}
 
-	if !c.local && c.apiKey == "" {
-		return nil, newError(
-			ErrorTypeAuthentication,
-			"api_key is required for remote streaming",
-			withSuggestion("Set RUNAGENT_API_KEY or pass Config.APIKey"),
-		)
+	if err := c.breaker.allow(); err != nil {
+		release()
+		return nil, err
 	}
-
-	endpoint := fmt.Sprintf("%s/agents/%s/run-stream", c.baseSocketURL, c.agentID)
-	if !c.local && c.apiKey != "" {
-		endpoint = appendToken(endpoint, c.apiKey)
+	reader, err := c.openStream(ctx, payload)
+	c.breaker.record(err)
+	if err != nil {
+		release()
+		return nil, attachRequestIDs(err, payload.RequestID, "")
 	}
+	stream := newStreamIterator(reader)
+	stream.onClose = release
+	stream.requestID = payload.RequestID
+	return stream, nil
+}
 
-	dialer := websocket.Dialer{
-		HandshakeTimeout: 30 * time.Second,
+// dialWebSocket dials the run-stream WebSocket and sends the bootstrap payload.
+func (c *RunAgentClient) dialWebSocket(ctx context.Context, payload apiRunRequest) (*websocket.Conn, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, newError(ErrorTypeValidation, "failed to serialize stream payload", withCause(err))
 	}
 
 	headers := http.Header{
 		"User-Agent": []string{userAgent()},
 	}
+	setRequestIDHeaders(headers, payload.RequestID, payload.IdempotencyKey)
 
-	conn, _, err := dialer.DialContext(ctx, endpoint, headers)
+	conn, err := c.upgradeWebSocket(ctx, headers)
 	if err != nil {
-		return nil, newError(
-			ErrorTypeConnection,
-			"failed to open WebSocket connection",
-			withCause(err),
-		)
+		return nil, err
 	}
 
 	if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
@@ -272,7 +359,52 @@ func (c *RunAgentClient) RunStream(ctx context.Context, values ...any) (*StreamI
 		return nil, newError(ErrorTypeConnection, "failed to send stream bootstrap payload", withCause(err))
 	}
 
-	return newStreamIterator(conn), nil
+	return conn, nil
+}
+
+// upgradeWebSocket performs the run-stream handshake, retrying once with
+// rotated credentials when the service rejects the key.
+func (c *RunAgentClient) upgradeWebSocket(ctx context.Context, headers http.Header) (*websocket.Conn, error) {
+	var key string
+	if !c.local {
+		var err error
+		if key, err = c.currentAPIKey(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	dial := func(key string) (*websocket.Conn, *http.Response, error) {
+		endpoint := fmt.Sprintf("%s/agents/%s/run-stream", c.baseSocketURL, c.agentID)
+		if key != "" {
+			endpoint = appendToken(endpoint, key)
+		}
+		conn, resp, err := c.wsDialer.DialContext(ctx, endpoint, headers)
+		c.rateLimiter.observe(resp)
+		return conn, resp, err
+	}
+
+	conn, resp, err := dial(key)
+	if err != nil && resp != nil && resp.StatusCode == http.StatusUnauthorized && key != "" {
+		if rotated, ok := c.refreshAPIKey(ctx, key); ok {
+			conn, resp, err = dial(rotated)
+		}
+	}
+	if err != nil {
+		// A rejected handshake is an HTTP error like any other, so auth,
+		// quota and server failures keep their types; openStream decides
+		// which of them fall back. Responses that are neither an error nor
+		// a 101 upgrade remain connection errors and always fall back.
+		if resp != nil && resp.StatusCode >= http.StatusBadRequest {
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+			return nil, translateHTTPError(resp.StatusCode, resp.Header, body)
+		}
+		opts := []func(*RunAgentError){withCause(err)}
+		if resp != nil {
+			opts = append(opts, withDetails(map[string]interface{}{"status": resp.StatusCode}))
+		}
+		return nil, newError(ErrorTypeConnection, "failed to open WebSocket connection", opts...)
+	}
+	return conn, nil
 }
 
 // RunStreamNative starts a streaming execution using native Go-shaped arguments.
@@ -284,6 +416,26 @@ func (c *RunAgentClient) RunStreamNative(ctx context.Context, values ...any) (*S
 	return c.RunStream(ctx, input)
 }
 
+// AgentID returns the agent the client targets.
+func (c *RunAgentClient) AgentID() string { return c.agentID }
+
+// Framework returns the framework whose adapter the client uses.
+func (c *RunAgentClient) Framework() Framework { return c.adapter.Framework() }
+
+// Messages normalizes a run output, or a joined stream output, into chat
+// messages using the client's framework adapter.
+func (c *RunAgentClient) Messages(output interface{}) []Message { return c.adapter.Messages(output) }
+
+// AgentConfig returns the runagent.config.json of a local agent found through
+// the registry, or nil when it was not discovered or could not be read.
+func (c *RunAgentClient) AgentConfig() *AgentConfig { return c.agentConfig }
+
+// EntrypointTag returns the entrypoint the client invokes.
+func (c *RunAgentClient) EntrypointTag() string { return c.entrypointTag }
+
+// Local reports whether the client targets a local agent.
+func (c *RunAgentClient) Local() bool { return c.local }
+
 // ExtraParams returns the extra metadata provided at construction.
 func (c *RunAgentClient) ExtraParams() map[string]interface{} {
 	copyMap := make(map[string]interface{}, len(c.extraParams))
@@ -430,6 +582,12 @@ type envConfig struct {
 	port           int
 	timeoutSeconds int
 	local          *bool
+	transport      string
+	profile        string
+	caFile         string
+	clientCert     string
+	clientKey      string
+	socketPath     string
 }
 
 func loadEnvConfig() envConfig {
@@ -437,6 +595,12 @@ func loadEnvConfig() envConfig {
 	cfg.apiKey = strings.TrimSpace(os.Getenv(constants.EnvAPIKey))
 	cfg.baseURL = strings.TrimSpace(os.Getenv(constants.EnvBaseURL))
 	cfg.host = strings.TrimSpace(os.Getenv(constants.EnvAgentHost))
+	cfg.transport = strings.TrimSpace(os.Getenv(constants.EnvTransport))
+	cfg.profile = strings.TrimSpace(os.Getenv(constants.EnvProfile))
+	cfg.caFile = strings.TrimSpace(os.Getenv(constants.EnvCAFile))
+	cfg.clientCert = strings.TrimSpace(os.Getenv(constants.EnvClientCert))
+	cfg.clientKey = strings.TrimSpace(os.Getenv(constants.EnvClientKey))
+	cfg.socketPath = strings.TrimSpace(os.Getenv(constants.EnvSocketPath))
 
 	if portStr := os.Getenv(constants.EnvAgentPort); portStr != "" {
 		if port, err := strconv.Atoi(portStr); err == nil {
@@ -459,26 +623,55 @@ func loadEnvConfig() envConfig {
 	return cfg
 }
 
-func discoverLocalAgent(agentID string) (string, int, error) {
+// loadProfile reads the selected profile from the user config file. A
+// missing or unreadable file is only an error when a profile was named.
+func loadProfile(name string) (*config.Profile, error) {
+	file, err := config.LoadFile()
+	if err != nil {
+		if name == "" {
+			return &config.Profile{}, nil
+		}
+		return nil, newError(
+			ErrorTypeValidation,
+			"failed to read profile configuration",
+			withCode("PROFILE_NOT_FOUND"),
+			withCause(err),
+		)
+	}
+
+	profile, resolved, err := file.ResolveProfile(name)
+	if err != nil {
+		return nil, newError(
+			ErrorTypeValidation,
+			err.Error(),
+			withCode("PROFILE_NOT_FOUND"),
+			withDetails(map[string]interface{}{"profile": resolved}),
+			withSuggestion("Add the profile to ~/.runagent/user_data.json or unset RUNAGENT_PROFILE"),
+		)
+	}
+	return profile, nil
+}
+
+func discoverLocalAgent(agentID string) (*db.Agent, error) {
 	svc, err := db.NewService("")
 	if err != nil {
-		return "", 0, newError(ErrorTypeConnection, "failed to open local agent registry", withCause(err))
+		return nil, newError(ErrorTypeConnection, "failed to open local agent registry", withCause(err))
 	}
 	defer svc.Close()
 
 	agent, err := svc.GetAgent(agentID)
 	if err != nil {
-		return "", 0, newError(ErrorTypeServer, "failed to lookup agent in local database", withCause(err))
+		return nil, newError(ErrorTypeServer, "failed to lookup agent in local database", withCause(err))
 	}
 	if agent == nil {
-		return "", 0, newError(
+		return nil, newError(
 			ErrorTypeValidation,
 			fmt.Sprintf("agent %s was not found locally", agentID),
 			withSuggestion("Start the agent locally or pass host/port overrides"),
 		)
 	}
 
-	return agent.Host, agent.Port, nil
+	return agent, nil
 }
 
 func normalizeRemoteBases(raw string) (string, string, error) {
@@ -550,7 +743,7 @@ func appendToken(uri, token string) string {
 	return parsed.String()
 }
 
-func translateHTTPError(status int, body []byte) error {
+func translateHTTPError(status int, header http.Header, body []byte) error {
 	apiErr := &apiErrorPayload{
 		Type:    ErrorTypeServer,
 		Message: fmt.Sprintf("server returned status %d", status),
@@ -568,6 +761,17 @@ func translateHTTPError(status int, body []byte) error {
 		if apiErr.Suggestion == "" {
 			apiErr.Suggestion = "Set RUNAGENT_API_KEY or pass Config.APIKey"
 		}
+	} else if status == http.StatusTooManyRequests {
+		apiErr.Code = "RATE_LIMITED"
+		if apiErr.Suggestion == "" {
+			apiErr.Suggestion = "Reduce request rate or configure Config.RateLimit"
+		}
+		if wait := retryAfter(header); wait > 0 {
+			if apiErr.Details == nil {
+				apiErr.Details = map[string]interface{}{}
+			}
+			apiErr.Details["retry_after_seconds"] = wait.Seconds()
+		}
 	} else if status >= 500 {
 		apiErr.Type = ErrorTypeServer
 	}
@@ -575,6 +779,24 @@ func translateHTTPError(status int, body []byte) error {
 	return newExecutionError(status, apiErr)
 }
 
+// applyAuth sets the bearer token for remote calls.
+func (c *RunAgentClient) applyAuth(req *http.Request) error {
+	if c.local {
+		return nil
+	}
+	key, err := c.currentAPIKey(req.Context())
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", key))
+	return nil
+}
+
+// isStreamTag reports whether the entrypoint tag must be invoked via streaming.
+func isStreamTag(tag string) bool {
+	return tag == "generic_stream" || tag == "stream" || strings.HasSuffix(strings.ToLower(tag), "_stream")
+}
+
 func userAgent() string {
 	return fmt.Sprintf("runagent-go/%s", Version)
 }
@@ -588,6 +810,8 @@ type kwToken struct {
 	v any
 }
 type kwsToken struct{ m map[string]any }
+type idempotencyKeyToken struct{ key string }
+type bypassCacheToken struct{}
 
 // Arg appends one positional argument.
 func Arg(v any) argToken { return argToken{v: v} }
@@ -601,6 +825,30 @@ func Kw(key string, value any) kwToken { return kwToken{k: key, v: value} }
 // Kws merges many keyword arguments from a map.
 func Kws(m map[string]any) kwsToken { return kwsToken{m: m} }
 
+// IdempotencyKey marks the run with a caller-supplied idempotency key so the
+// server can deduplicate retries. It is not sent as an argument.
+func IdempotencyKey(key string) idempotencyKeyToken { return idempotencyKeyToken{key: key} }
+
+// BypassCache forces a fresh run when Config.Cache is enabled. The result
+// still refreshes the cache. It is not sent as an argument.
+func BypassCache() bypassCacheToken { return bypassCacheToken{} }
+
+// prepareInput coerces values, shapes Message and State inputs for the
+// agent's framework and validates the result before anything is sent.
+func (c *RunAgentClient) prepareInput(ctx context.Context, values ...any) (RunInput, error) {
+	input, err := coerceToRunInput(values...)
+	if err != nil {
+		return RunInput{}, err
+	}
+	if input, err = c.adapter.AdaptInput(input); err != nil {
+		return RunInput{}, err
+	}
+	if err := c.validateInput(ctx, input); err != nil {
+		return RunInput{}, err
+	}
+	return input, nil
+}
+
 func coerceToRunInput(values ...any) (RunInput, error) {
 	var input RunInput
 	var haveArgs bool
@@ -632,10 +880,56 @@ func coerceToRunInput(values ...any) (RunInput, error) {
 			for k, val := range t.m {
 				addKw(k, val)
 			}
+		case idempotencyKeyToken:
+			input.IdempotencyKey = t.key
+		case bypassCacheToken:
+			input.BypassCache = true
+		case Message:
+			input.Messages = append(input.Messages, t)
+		case []Message:
+			input.Messages = append(input.Messages, t...)
+		case stateToken:
+			if input.State == nil {
+				input.State = map[string]any{}
+			}
+			for k, val := range t.m {
+				input.State[k] = val
+			}
 		case map[string]any:
 			for k, val := range t {
 				addKw(k, val)
 			}
+		case RunInput:
+			for _, item := range t.InputArgs {
+				appendArg(item)
+			}
+			for k, val := range t.InputKwargs {
+				addKw(k, val)
+			}
+			if t.TimeoutSeconds > 0 {
+				input.TimeoutSeconds = t.TimeoutSeconds
+			}
+			if t.AsyncExecution != nil {
+				input.AsyncExecution = t.AsyncExecution
+			}
+			if t.ThreadID != "" {
+				input.ThreadID = t.ThreadID
+			}
+			if t.IdempotencyKey != "" {
+				input.IdempotencyKey = t.IdempotencyKey
+			}
+			if t.BypassCache {
+				input.BypassCache = true
+			}
+			input.Messages = append(input.Messages, t.Messages...)
+			if t.State != nil {
+				if input.State == nil {
+					input.State = map[string]any{}
+				}
+				for k, val := range t.State {
+					input.State[k] = val
+				}
+			}
 		default:
 			// Reject raw []any to avoid ambiguity with Args(...).
 			if isSliceOfAny(t) {
@@ -699,24 +993,46 @@ func structToMap(v any) (map[string]any, error) {
 
 // GetArchitecture fetches the agent architecture and normalizes both envelope and legacy formats.
 func (c *RunAgentClient) GetArchitecture(ctx context.Context) (*AgentArchitecture, error) {
+	key := c.architectureCacheKey()
+	if c.cache != nil {
+		if raw, ok, err := c.cache.cfg.Backend.Get(ctx, key); err == nil && ok {
+			var cached AgentArchitecture
+			if json.Unmarshal(raw, &cached) == nil {
+				return &cached, nil
+			}
+		}
+	}
+
+	arch, err := c.fetchArchitecture(ctx)
+	if err != nil {
+		// A local agent that is not running still describes itself in its
+		// runagent.config.json.
+		if runErr, ok := AsRunAgentError(err); ok && runErr.Type == ErrorTypeConnection &&
+			c.agentConfig != nil && len(c.agentConfig.AgentArchitecture.Entrypoints) > 0 {
+			return c.agentConfig.Architecture(), nil
+		}
+		return nil, err
+	}
+	if c.cache != nil {
+		if raw, err := json.Marshal(arch); err == nil {
+			c.cache.cfg.Backend.Set(ctx, key, raw, c.cache.cfg.ArchitectureTTL)
+		}
+	}
+	return arch, nil
+}
+
+func (c *RunAgentClient) fetchArchitecture(ctx context.Context) (*AgentArchitecture, error) {
 	endpoint := fmt.Sprintf("%s/agents/%s/architecture", c.baseRESTURL, c.agentID)
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
 		return nil, newError(ErrorTypeUnknown, "failed to create request", withCause(err))
 	}
-	if !c.local {
-		if c.apiKey == "" {
-			return nil, newError(
-				ErrorTypeAuthentication,
-				"api_key is required for remote calls",
-				withSuggestion("Set RUNAGENT_API_KEY or pass Config.APIKey"),
-			)
-		}
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
+	if err := c.applyAuth(req); err != nil {
+		return nil, err
 	}
 	req.Header.Set("User-Agent", userAgent())
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.doAuthorized(c.httpClient, req)
 	if err != nil {
 		return nil, newError(ErrorTypeConnection, "failed to reach RunAgent service", withCause(err))
 	}
@@ -728,7 +1044,7 @@ func (c *RunAgentClient) GetArchitecture(ctx context.Context) (*AgentArchitectur
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, translateHTTPError(resp.StatusCode, body)
+		return nil, translateHTTPError(resp.StatusCode, resp.Header, body)
 	}
 
 	// Try envelope format
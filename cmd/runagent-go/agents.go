@@ -0,0 +1,234 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/runagent-dev/runagent-go"
+	"github.com/runagent-dev/runagent-go/internal/db"
+)
+
+func cmdAgents(args []string) error {
+	if len(args) == 0 {
+		return usagef("usage: runagent-go agents list|add|rm")
+	}
+	switch args[0] {
+	case "list", "ls":
+		return agentsList(args[1:])
+	case "add":
+		return agentsAdd(args[1:])
+	case "rm", "remove":
+		return agentsRemove(args[1:])
+	default:
+		return usagef("unknown agents subcommand %q", args[0])
+	}
+}
+
+// openRegistry opens the local registry, reporting failures as SDK errors so
+// they map to the connection exit code.
+func openRegistry() (*db.Service, error) {
+	svc, err := db.NewService("")
+	if err != nil {
+		return nil, &runagent.RunAgentError{
+			Type:    runagent.ErrorTypeConnection,
+			Message: "failed to open local agent registry",
+			Cause:   err,
+		}
+	}
+	return svc, nil
+}
+
+func agentsList(args []string) error {
+	fs := flag.NewFlagSet("agents list", flag.ContinueOnError)
+	format := outputFlag(fs, formatTable)
+	if _, err := parseInterspersed(fs, args); err != nil {
+		return err
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	svc, err := openRegistry()
+	if err != nil {
+		return err
+	}
+	defer svc.Close()
+
+	agents, err := svc.ListAgents()
+	if err != nil {
+		return err
+	}
+	if *format == formatJSON {
+		if agents == nil {
+			agents = []*db.Agent{}
+		}
+		return printJSON(agents)
+	}
+
+	rows := make([][]string, 0, len(agents))
+	for _, agent := range agents {
+		address := fmt.Sprintf("%s:%d", agent.Host, agent.Port)
+		if agent.SocketPath != "" {
+			address = "unix://" + agent.SocketPath
+		}
+		rows = append(rows, []string{
+			agent.AgentID,
+			orDash(agent.Framework),
+			agent.Status,
+			address,
+			strconv.FormatInt(agent.RunCount, 10),
+			agent.DeployedAt.Local().Format(time.RFC3339),
+		})
+	}
+	return printTable([]string{"agent_id", "framework", "status", "address", "runs", "deployed_at"}, rows)
+}
+
+func agentsAdd(args []string) error {
+	fs := flag.NewFlagSet("agents add", flag.ContinueOnError)
+	agentID := fs.String("agent-id", "", "agent ID (required)")
+	path := fs.String("path", "", "agent source directory (required)")
+	host := fs.String("host", "", "host the agent listens on")
+	port := fs.Int("port", 0, "port the agent listens on")
+	socket := fs.String("socket", "", "Unix socket the agent listens on")
+	framework := fs.String("framework", "", "agent framework")
+	format := outputFlag(fs, formatTable)
+	if _, err := parseInterspersed(fs, args); err != nil {
+		return err
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+	if *agentID == "" || *path == "" {
+		return usagef("--agent-id and --path are required")
+	}
+
+	svc, err := openRegistry()
+	if err != nil {
+		return err
+	}
+	defer svc.Close()
+
+	result, err := svc.AddAgent(&db.Agent{
+		AgentID:    *agentID,
+		AgentPath:  *path,
+		Host:       *host,
+		Port:       *port,
+		SocketPath: *socket,
+		Framework:  *framework,
+	})
+	if err != nil {
+		return err
+	}
+	if !result.Success {
+		return &runagent.RunAgentError{
+			Type:    runagent.ErrorTypeValidation,
+			Code:    result.Code,
+			Message: result.Error,
+		}
+	}
+	if *format == formatJSON {
+		return printJSON(result)
+	}
+	fmt.Printf("%s (%s)\n", result.Message, result.Address)
+	return nil
+}
+
+func agentsRemove(args []string) error {
+	fs := flag.NewFlagSet("agents rm", flag.ContinueOnError)
+	ids, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return usagef("usage: runagent-go agents rm AGENT_ID...")
+	}
+
+	svc, err := openRegistry()
+	if err != nil {
+		return err
+	}
+	defer svc.Close()
+
+	for _, id := range ids {
+		removed, err := svc.RemoveAgent(id)
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return &runagent.RunAgentError{
+				Type:    runagent.ErrorTypeValidation,
+				Code:    "AGENT_NOT_FOUND",
+				Message: fmt.Sprintf("agent %s was not found locally", id),
+			}
+		}
+		fmt.Printf("Removed agent %s\n", id)
+	}
+	return nil
+}
+
+func cmdRuns(args []string) error {
+	fs := flag.NewFlagSet("runs", flag.ContinueOnError)
+	agentID := fs.String("agent-id", "", "only show runs for this agent")
+	limit := fs.Int("limit", 20, "maximum number of runs to show; 0 for all")
+	format := outputFlag(fs, formatTable)
+	if _, err := parseInterspersed(fs, args); err != nil {
+		return err
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	svc, err := openRegistry()
+	if err != nil {
+		return err
+	}
+	defer svc.Close()
+
+	runs, err := svc.ListAgentRuns(*agentID, *limit)
+	if err != nil {
+		return err
+	}
+	if *format == formatJSON {
+		if runs == nil {
+			runs = []*db.AgentRun{}
+		}
+		return printJSON(runs)
+	}
+
+	rows := make([][]string, 0, len(runs))
+	for _, run := range runs {
+		status := "ok"
+		if !run.Success {
+			status = "error"
+		}
+		duration := "-"
+		if run.ExecutionTime != nil {
+			duration = (time.Duration(*run.ExecutionTime * float64(time.Second))).Round(time.Millisecond).String()
+		}
+		detail := "-"
+		if run.ErrorMessage != nil {
+			detail = truncate(*run.ErrorMessage, 60)
+		} else if run.OutputData != nil {
+			detail = truncate(*run.OutputData, 60)
+		}
+		rows = append(rows, []string{
+			strconv.FormatInt(run.ID, 10),
+			run.AgentID,
+			status,
+			duration,
+			run.StartedAt.Local().Format(time.RFC3339),
+			detail,
+		})
+	}
+	return printTable([]string{"id", "agent_id", "status", "duration", "started_at", "output"}, rows)
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/runagent-dev/runagent-go"
+)
+
+func cmdBench(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	var cf clientFlags
+	cf.register(fs, true)
+	inputPath := fs.String("input", "", "JSON file with kwargs (object) or args (array); - for stdin")
+	duration := fs.Duration("duration", 30*time.Second, "how long to send requests")
+	concurrency := fs.Int("concurrency", 1, "requests in flight at once")
+	rps := fs.Float64("rps", 0, "target requests per second; 0 sends back to back")
+	hgrm := fs.String("hgrm", "", "write the latency histogram (.hgrm) to this file")
+	format := outputFlag(fs, formatTable)
+
+	pairs, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+	if cf.tag == "" {
+		return usagef("--tag is required")
+	}
+	if *duration <= 0 || *concurrency <= 0 || *rps < 0 {
+		return usagef("--duration and --concurrency must be positive and --rps not negative")
+	}
+	input, err := buildInput(*inputPath, pairs)
+	if err != nil {
+		return err
+	}
+	client, err := cf.client(fs, cf.tag)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	result, err := runagent.Benchmark(ctx, client, runagent.BenchmarkConfig{
+		Duration:    *duration,
+		Concurrency: *concurrency,
+		RPS:         *rps,
+		Input:       []any{input},
+	})
+	if err != nil {
+		return err
+	}
+
+	if *hgrm != "" {
+		if err := writeHistogram(*hgrm, result.LatencyHistogram); err != nil {
+			return err
+		}
+	}
+	if *format == formatJSON {
+		return printJSON(result)
+	}
+	return printBenchResult(result)
+}
+
+func writeHistogram(path string, h *runagent.Histogram) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return usagef("failed to create %s: %v", path, err)
+	}
+	if err := h.WritePercentileDistribution(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func printBenchResult(result *runagent.BenchmarkResult) error {
+	ms := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+	rows := [][]string{
+		{"requests", strconv.FormatInt(result.Requests, 10)},
+		{"successes", strconv.FormatInt(result.Successes, 10)},
+		{"failures", strconv.FormatInt(result.Failures, 10)},
+		{"elapsed", (time.Duration(result.Elapsed * float64(time.Second))).Round(time.Millisecond).String()},
+		{"throughput", fmt.Sprintf("%.2f req/s", result.Throughput)},
+	}
+	if err := printTable([]string{"metric", "value"}, rows); err != nil {
+		return err
+	}
+	fmt.Println()
+
+	summaries := []struct {
+		name    string
+		summary *runagent.LatencySummary
+	}{{"latency", &result.Latency}, {"first_chunk", result.FirstChunk}}
+	rows = rows[:0]
+	for _, s := range summaries {
+		if s.summary == nil {
+			continue
+		}
+		rows = append(rows, []string{
+			s.name, strconv.FormatInt(s.summary.Count, 10),
+			ms(s.summary.Min), ms(s.summary.Mean), ms(s.summary.P50), ms(s.summary.P90),
+			ms(s.summary.P95), ms(s.summary.P99), ms(s.summary.P999), ms(s.summary.Max),
+		})
+	}
+	if err := printTable([]string{"ms", "count", "min", "mean", "p50", "p90", "p95", "p99", "p99.9", "max"}, rows); err != nil {
+		return err
+	}
+
+	if len(result.Errors) == 0 {
+		return nil
+	}
+	fmt.Println()
+	rows = rows[:0]
+	for _, e := range result.Errors {
+		rows = append(rows, []string{string(e.Type), orDash(e.Code), strconv.FormatInt(e.Count, 10), truncate(e.Example, 80)})
+	}
+	return printTable([]string{"type", "code", "count", "example"}, rows)
+}
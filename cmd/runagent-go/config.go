@@ -0,0 +1,253 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/runagent-dev/runagent-go"
+	"github.com/runagent-dev/runagent-go/internal/config"
+	"github.com/runagent-dev/runagent-go/internal/utils"
+)
+
+func cmdConfig(args []string) error {
+	if len(args) == 0 {
+		return usagef("usage: runagent-go config list|show|set|rm|use")
+	}
+	switch args[0] {
+	case "list", "ls":
+		return configList(args[1:])
+	case "show":
+		return configShow(args[1:])
+	case "set":
+		return configSet(args[1:])
+	case "rm", "remove":
+		return configRemove(args[1:])
+	case "use":
+		return configUse(args[1:])
+	default:
+		return usagef("unknown config subcommand %q", args[0])
+	}
+}
+
+// loadConfigFile reads user_data.json without environment overrides so that
+// saving never persists values that only came from the environment.
+func loadConfigFile() (*config.Config, error) {
+	cfg, err := config.LoadFile()
+	if err != nil {
+		return nil, &runagent.RunAgentError{
+			Type:    runagent.ErrorTypeValidation,
+			Message: "failed to read configuration",
+			Cause:   err,
+		}
+	}
+	return cfg, nil
+}
+
+func saveConfigFile(cfg *config.Config) error {
+	if err := cfg.Save(); err != nil {
+		return &runagent.RunAgentError{
+			Type:    runagent.ErrorTypeValidation,
+			Message: "failed to save configuration",
+			Cause:   err,
+		}
+	}
+	return nil
+}
+
+func profileNotFound(name string) error {
+	return &runagent.RunAgentError{
+		Type:    runagent.ErrorTypeValidation,
+		Code:    "PROFILE_NOT_FOUND",
+		Message: fmt.Sprintf("profile %q not found", name),
+	}
+}
+
+func configList(args []string) error {
+	fs := flag.NewFlagSet("config list", flag.ContinueOnError)
+	format := outputFlag(fs, formatTable)
+	if _, err := parseInterspersed(fs, args); err != nil {
+		return err
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfigFile()
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if *format == formatJSON {
+		return printJSON(map[string]interface{}{
+			"default_profile": cfg.DefaultProfile,
+			"profiles":        names,
+		})
+	}
+
+	rows := make([][]string, 0, len(names))
+	for _, name := range names {
+		profile := cfg.Profiles[name]
+		marker := ""
+		if name == cfg.DefaultProfile {
+			marker = "*"
+		}
+		local := "-"
+		if profile.Local != nil {
+			local = strconv.FormatBool(*profile.Local)
+		}
+		timeout := "-"
+		if profile.TimeoutSeconds > 0 {
+			timeout = strconv.Itoa(profile.TimeoutSeconds)
+		}
+		rows = append(rows, []string{marker, name, orDash(profile.BaseURL), orDash(utils.MaskSecret(profile.APIKey)), timeout, local})
+	}
+	return printTable([]string{"", "profile", "base_url", "api_key", "timeout", "local"}, rows)
+}
+
+func configShow(args []string) error {
+	fs := flag.NewFlagSet("config show", flag.ContinueOnError)
+	format := outputFlag(fs, formatJSON)
+	names, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfigFile()
+	if err != nil {
+		return err
+	}
+
+	if len(names) == 0 {
+		return printValue(cfg.GetStatus(), *format)
+	}
+	profile, ok := cfg.Profiles[names[0]]
+	if !ok {
+		return profileNotFound(names[0])
+	}
+	masked := *profile
+	masked.APIKey = orDash(utils.MaskSecret(profile.APIKey))
+	if *format == formatJSON {
+		return printJSON(masked)
+	}
+	return printValue(map[string]interface{}{
+		"base_url":        masked.BaseURL,
+		"api_key":         masked.APIKey,
+		"timeout_seconds": masked.TimeoutSeconds,
+		"local":           masked.Local,
+	}, *format)
+}
+
+func configSet(args []string) error {
+	fs := flag.NewFlagSet("config set", flag.ContinueOnError)
+	baseURL := fs.String("base-url", "", "base URL for the profile")
+	apiKey := fs.String("api-key", "", "API key for the profile")
+	timeout := fs.Int("timeout", 0, "default timeout in seconds")
+	local := fs.Bool("local", false, "target local agents")
+	makeDefault := fs.Bool("default", false, "make this the default profile")
+	names, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(names) != 1 {
+		return usagef("usage: runagent-go config set NAME [--base-url URL] [--api-key KEY] [--timeout N] [--local] [--default]")
+	}
+
+	cfg, err := loadConfigFile()
+	if err != nil {
+		return err
+	}
+
+	// Update only the fields given on the command line.
+	profile := &config.Profile{}
+	if existing, ok := cfg.Profiles[names[0]]; ok {
+		copied := *existing
+		profile = &copied
+	}
+	fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "base-url":
+			profile.BaseURL = *baseURL
+		case "api-key":
+			profile.APIKey = *apiKey
+		case "timeout":
+			profile.TimeoutSeconds = *timeout
+		case "local":
+			profile.Local = runagent.Bool(*local)
+		}
+	})
+	cfg.SetProfile(names[0], profile)
+	if *makeDefault {
+		cfg.DefaultProfile = names[0]
+	}
+
+	if err := saveConfigFile(cfg); err != nil {
+		return err
+	}
+	fmt.Printf("Saved profile %s\n", names[0])
+	return nil
+}
+
+func configRemove(args []string) error {
+	fs := flag.NewFlagSet("config rm", flag.ContinueOnError)
+	names, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return usagef("usage: runagent-go config rm NAME...")
+	}
+
+	cfg, err := loadConfigFile()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if _, ok := cfg.Profiles[name]; !ok {
+			return profileNotFound(name)
+		}
+		cfg.DeleteProfile(name)
+	}
+	if err := saveConfigFile(cfg); err != nil {
+		return err
+	}
+	for _, name := range names {
+		fmt.Printf("Removed profile %s\n", name)
+	}
+	return nil
+}
+
+func configUse(args []string) error {
+	fs := flag.NewFlagSet("config use", flag.ContinueOnError)
+	names, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(names) != 1 {
+		return usagef("usage: runagent-go config use NAME")
+	}
+
+	cfg, err := loadConfigFile()
+	if err != nil {
+		return err
+	}
+	if _, ok := cfg.Profiles[names[0]]; !ok {
+		return profileNotFound(names[0])
+	}
+	cfg.DefaultProfile = names[0]
+	if err := saveConfigFile(cfg); err != nil {
+		return err
+	}
+	fmt.Printf("Default profile is now %s\n", names[0])
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/runagent-dev/runagent-go"
+)
+
+func cmdDoctor(args []string) error {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	var cf clientFlags
+	cf.register(fs, true)
+	format := outputFlag(fs, formatTable)
+	if _, err := parseInterspersed(fs, args); err != nil {
+		return err
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+	cfg, err := cf.config(fs, cf.tag)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	report := runagent.Diagnose(ctx, cfg)
+	if *format == formatJSON {
+		if err := printJSON(report); err != nil {
+			return err
+		}
+		return report.Err()
+	}
+
+	rows := make([][]string, 0, len(report.Config))
+	for _, value := range report.Config {
+		rows = append(rows, []string{value.Name, value.Value, string(value.Source)})
+	}
+	if err := printTable([]string{"setting", "value", "source"}, rows); err != nil {
+		return err
+	}
+	fmt.Println()
+
+	rows = rows[:0]
+	for _, check := range report.Checks {
+		rows = append(rows, []string{check.Name, string(check.Status), check.Duration.Round(time.Millisecond).String(), truncate(check.Message, 100)})
+	}
+	if err := printTable([]string{"check", "status", "time", "detail"}, rows); err != nil {
+		return err
+	}
+
+	if suggestions := report.Suggestions(); len(suggestions) > 0 {
+		fmt.Println()
+		fmt.Println("Suggestions:")
+		for _, suggestion := range suggestions {
+			fmt.Printf("  - %s\n", suggestion)
+		}
+	}
+	return report.Err()
+}
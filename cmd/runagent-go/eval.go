@@ -0,0 +1,141 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/runagent-dev/runagent-go"
+	"github.com/runagent-dev/runagent-go/eval"
+)
+
+func cmdEval(args []string) error {
+	fs := flag.NewFlagSet("eval", flag.ContinueOnError)
+	var cf clientFlags
+	cf.register(fs, true)
+	dataset := fs.String("dataset", "", "JSONL dataset of cases (required)")
+	baseline := fs.String("baseline", "", "saved report to compare against")
+	save := fs.String("save", "", "write the report to this file")
+	scorer := fs.String("scorer", eval.ScorerExact, "default scorer: exact, json_subset, regex or numeric")
+	concurrency := fs.Int("concurrency", runagent.DefaultBatchConcurrency, "runs in flight at once")
+	label := fs.String("label", "", "label stored in the report, e.g. a deployment")
+	format := outputFlag(fs, formatTable)
+
+	if _, err := parseInterspersed(fs, args); err != nil {
+		return err
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+	if cf.tag == "" || *dataset == "" {
+		return usagef("--tag and --dataset are required")
+	}
+	cases, err := eval.LoadDataset(*dataset)
+	if err != nil {
+		return usagef("failed to load dataset: %v", err)
+	}
+	var base *eval.Report
+	if *baseline != "" {
+		if base, err = eval.LoadReport(*baseline); err != nil {
+			return usagef("failed to load baseline: %v", err)
+		}
+	}
+	defaultScorer, ok := eval.Builtin(*scorer)
+	if !ok {
+		return usagef("unknown scorer %q", *scorer)
+	}
+	client, err := cf.client(fs, cf.tag)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	report, err := eval.Run(ctx, client, cases, eval.Options{
+		Concurrency: *concurrency,
+		Scorer:      defaultScorer,
+		ScorerName:  *scorer,
+		Label:       *label,
+	})
+	if err != nil {
+		return err
+	}
+	if *save != "" {
+		if err := report.Save(*save); err != nil {
+			return usagef("failed to save report: %v", err)
+		}
+	}
+
+	var cmp *eval.Comparison
+	if base != nil {
+		cmp = eval.Compare(base, report)
+	}
+	if *format == formatJSON {
+		if err := printJSON(map[string]interface{}{"report": report, "comparison": cmp}); err != nil {
+			return err
+		}
+	} else if err := printEvalReport(report, cmp); err != nil {
+		return err
+	}
+
+	if cmp != nil && cmp.HasRegressions() {
+		return &runagent.RunAgentError{
+			Type:    runagent.ErrorTypeValidation,
+			Code:    "EVAL_REGRESSION",
+			Message: fmt.Sprintf("%d cases regressed against %s", len(cmp.Regressions), *baseline),
+		}
+	}
+	return nil
+}
+
+func printEvalReport(report *eval.Report, cmp *eval.Comparison) error {
+	rows := make([][]string, 0, len(report.Results))
+	for _, r := range report.Results {
+		status := "pass"
+		detail := r.Reason
+		switch {
+		case r.Error != "":
+			status, detail = "error", r.Error
+		case !r.Pass:
+			status = "fail"
+		}
+		rows = append(rows, []string{r.ID, status, strconv.FormatFloat(r.Score, 'f', 2, 64), r.Scorer, orDash(truncate(detail, 80))})
+	}
+	if err := printTable([]string{"case", "status", "score", "scorer", "detail"}, rows); err != nil {
+		return err
+	}
+
+	s := report.Summary
+	fmt.Printf("\n%d cases: %d passed, %d failed, %d errored; pass rate %.1f%%, mean score %.3f\n",
+		s.Total, s.Passed, s.Failed, s.Errored, s.PassRate*100, s.MeanScore)
+	if cmp == nil {
+		return nil
+	}
+
+	fmt.Printf("vs baseline: pass rate %+.1f pts, mean score %+.3f; %d regressed, %d improved, %d added, %d removed, %d unchanged\n",
+		cmp.PassRateDelta*100, cmp.MeanScoreDelta, len(cmp.Regressions), len(cmp.Improvements), len(cmp.Added), len(cmp.Removed), cmp.Unchanged)
+	if len(cmp.Regressions) == 0 {
+		return nil
+	}
+	fmt.Println()
+	rows = rows[:0]
+	for _, d := range cmp.Regressions {
+		rows = append(rows, []string{
+			d.ID,
+			strconv.FormatFloat(d.Baseline.Score, 'f', 2, 64),
+			strconv.FormatFloat(d.Current.Score, 'f', 2, 64),
+			orDash(truncate(firstNonEmpty(d.Current.Error, d.Current.Reason), 80)),
+		})
+	}
+	return printTable([]string{"regressed", "baseline", "current", "detail"}, rows)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
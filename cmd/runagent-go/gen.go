@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/runagent-dev/runagent-go"
+	"github.com/runagent-dev/runagent-go/internal/codegen"
+)
+
+func cmdGen(args []string) error {
+	fs := flag.NewFlagSet("gen", flag.ContinueOnError)
+	var cf clientFlags
+	cf.register(fs, false)
+	archPath := fs.String("arch", "", "architecture JSON, runagent.config.json or agent directory; omit to fetch from the agent")
+	pkg := fs.String("package", "", "package name; defaults to the output directory name")
+	out := fs.String("out", "", "output file; defaults to stdout")
+
+	if _, err := parseInterspersed(fs, args); err != nil {
+		return err
+	}
+
+	var arch *runagent.AgentArchitecture
+	var source string
+	if *archPath != "" {
+		var err error
+		if arch, err = codegen.ReadArchitecture(*archPath); err != nil {
+			if _, ok := runagent.AsRunAgentError(err); ok {
+				return err
+			}
+			return &runagent.RunAgentError{
+				Type:    runagent.ErrorTypeValidation,
+				Message: "failed to read architecture",
+				Cause:   err,
+			}
+		}
+		source = filepath.ToSlash(*archPath)
+	} else {
+		client, err := cf.client(fs, "generic")
+		if err != nil {
+			return err
+		}
+		ctx, cancel := signalContext()
+		defer cancel()
+		if arch, err = client.GetArchitecture(ctx); err != nil {
+			return err
+		}
+		source = fmt.Sprintf("architecture of agent %s", cf.agentID)
+	}
+
+	name := *pkg
+	if name == "" {
+		name = "agentclient"
+		if *out != "" {
+			if abs, err := filepath.Abs(filepath.Dir(*out)); err == nil {
+				name = filepath.Base(abs)
+			}
+		}
+	}
+
+	src, err := codegen.Generate(arch, codegen.Options{
+		Package: name,
+		AgentID: cf.agentID,
+		Source:  source,
+	})
+	if err != nil {
+		return usagef("%v", err)
+	}
+	if *out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(*out, src, 0644)
+}
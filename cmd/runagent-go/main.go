@@ -0,0 +1,140 @@
+// Command runagent-go is a command-line client for RunAgent agents.
+//
+// Usage:
+//
+//	runagent-go run    --agent-id ID --tag TAG [key=value ...] [--input file.json]
+//	runagent-go stream --agent-id ID --tag TAG [key=value ...]
+//	runagent-go arch   --agent-id ID
+//	runagent-go doctor --agent-id ID [--tag TAG]
+//	runagent-go bench  --agent-id ID --tag TAG [--duration 30s] [--concurrency N] [--rps R]
+//	runagent-go eval   --agent-id ID --tag TAG --dataset cases.jsonl [--baseline report.json] [--save report.json]
+//	runagent-go gen    [--agent-id ID | --arch runagent.config.json] [--package NAME] [--out FILE]
+//	runagent-go agents list|add|rm
+//	runagent-go runs   [--agent-id ID] [--limit N]
+//	runagent-go config list|show|set|rm|use
+//	runagent-go version
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/runagent-dev/runagent-go"
+)
+
+// Exit codes map to runagent.ErrorType so scripts can branch on failures.
+const (
+	exitOK             = 0
+	exitUnknown        = 1
+	exitUsage          = 2
+	exitValidation     = 3
+	exitAuthentication = 4
+	exitPermission     = 5
+	exitConnection     = 6
+	exitServer         = 7
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{"run", "invoke a non-streaming entrypoint", cmdRun},
+	{"stream", "invoke a streaming entrypoint and print chunks live", cmdStream},
+	{"arch", "show the agent's entrypoints", cmdArch},
+	{"doctor", "diagnose connectivity and configuration", cmdDoctor},
+	{"bench", "load test an entrypoint and report latency percentiles", cmdBench},
+	{"eval", "score a JSONL dataset and compare against a baseline", cmdEval},
+	{"gen", "generate a typed Go client from an agent's architecture", cmdGen},
+	{"agents", "manage the local agent registry (list, add, rm)", cmdAgents},
+	{"runs", "show recorded run history", cmdRuns},
+	{"config", "manage configuration profiles (list, show, set, rm, use)", cmdConfig},
+	{"version", "print the SDK version", cmdVersion},
+}
+
+func main() {
+	os.Exit(execute(os.Args[1:]))
+}
+
+func execute(args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		usage(os.Stdout)
+		return exitOK
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return exitCode(cmd.run(args[1:]))
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", args[0])
+	usage(os.Stderr)
+	return exitUsage
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: runagent-go <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'runagent-go <command> -h' for command flags.")
+}
+
+// usageError reports invalid command-line usage.
+type usageError struct{ msg string }
+
+func (e *usageError) Error() string { return e.msg }
+
+func usagef(format string, args ...interface{}) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// exitCode prints err and maps it to the process exit code.
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+
+	var usageErr *usageError
+	if errors.As(err, &usageErr) {
+		fmt.Fprintln(os.Stderr, "error:", usageErr.msg)
+		return exitUsage
+	}
+
+	fmt.Fprintln(os.Stderr, "error:", err)
+	runErr, ok := runagent.AsRunAgentError(err)
+	if !ok {
+		return exitUnknown
+	}
+	switch runErr.Type {
+	case runagent.ErrorTypeValidation:
+		return exitValidation
+	case runagent.ErrorTypeAuthentication:
+		return exitAuthentication
+	case runagent.ErrorTypePermission:
+		return exitPermission
+	case runagent.ErrorTypeConnection:
+		return exitConnection
+	case runagent.ErrorTypeServer:
+		return exitServer
+	default:
+		return exitUnknown
+	}
+}
+
+func cmdVersion(args []string) error {
+	fmt.Printf("runagent-go %s\n", runagent.Version)
+	return nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	formatJSON  = "json"
+	formatTable = "table"
+)
+
+// outputFlag registers -o/--output with the given default format.
+func outputFlag(fs *flag.FlagSet, def string) *string {
+	format := new(string)
+	*format = def
+	usage := "output format: json or table"
+	fs.StringVar(format, "o", def, usage)
+	fs.StringVar(format, "output", def, usage)
+	return format
+}
+
+func checkFormat(format string) error {
+	if format != formatJSON && format != formatTable {
+		return usagef("unknown output format %q (use json or table)", format)
+	}
+	return nil
+}
+
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// printTable writes rows as aligned columns under upper-case headers.
+func printTable(headers []string, rows [][]string) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.ToUpper(strings.Join(headers, "\t")))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// printValue renders an arbitrary run output. Tables show maps as
+// key/value rows and fall back to JSON for other shapes.
+func printValue(v interface{}, format string) error {
+	if format == formatJSON {
+		return printJSON(v)
+	}
+
+	switch t := v.(type) {
+	case string:
+		fmt.Println(t)
+		return nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		rows := make([][]string, 0, len(keys))
+		for _, k := range keys {
+			rows = append(rows, []string{k, cell(t[k])})
+		}
+		return printTable([]string{"key", "value"}, rows)
+	default:
+		return printJSON(v)
+	}
+}
+
+// cell renders a value on a single table line.
+func cell(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return "-"
+	case string:
+		return t
+	default:
+		data, err := json.Marshal(t)
+		if err != nil {
+			return fmt.Sprint(t)
+		}
+		return string(data)
+	}
+}
@@ -0,0 +1,383 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/runagent-dev/runagent-go"
+	"github.com/runagent-dev/runagent-go/internal/db"
+)
+
+// clientFlags are the connection flags shared by commands that talk to an agent.
+type clientFlags struct {
+	agentID string
+	tag     string
+	local   bool
+	host    string
+	port    int
+	socket  string
+	baseURL string
+	apiKey  string
+	profile string
+	timeout int
+}
+
+func (f *clientFlags) register(fs *flag.FlagSet, needTag bool) {
+	fs.StringVar(&f.agentID, "agent-id", "", "agent ID (required)")
+	if needTag {
+		fs.StringVar(&f.tag, "tag", "", "entrypoint tag")
+	}
+	fs.BoolVar(&f.local, "local", false, "target a local agent")
+	fs.StringVar(&f.host, "host", "", "local agent host")
+	fs.IntVar(&f.port, "port", 0, "local agent port")
+	fs.StringVar(&f.socket, "socket", "", "local agent Unix socket path")
+	fs.StringVar(&f.baseURL, "base-url", "", "remote base URL")
+	fs.StringVar(&f.apiKey, "api-key", "", "API key for remote agents")
+	fs.StringVar(&f.profile, "profile", "", "configuration profile")
+	fs.IntVar(&f.timeout, "timeout", 0, "request timeout in seconds")
+}
+
+func (f *clientFlags) client(fs *flag.FlagSet, tag string) (*runagent.RunAgentClient, error) {
+	cfg, err := f.config(fs, tag)
+	if err != nil {
+		return nil, err
+	}
+	return runagent.NewRunAgentClient(cfg)
+}
+
+func (f *clientFlags) config(fs *flag.FlagSet, tag string) (runagent.Config, error) {
+	if f.agentID == "" {
+		return runagent.Config{}, usagef("--agent-id is required")
+	}
+
+	cfg := runagent.Config{
+		AgentID:        f.agentID,
+		EntrypointTag:  tag,
+		Host:           f.host,
+		Port:           f.port,
+		BaseURL:        f.baseURL,
+		APIKey:         f.apiKey,
+		TimeoutSeconds: f.timeout,
+		Profile:        f.profile,
+		SocketPath:     f.socket,
+	}
+	// Only pass --local when given so env and profile settings still apply.
+	fs.Visit(func(fl *flag.Flag) {
+		if fl.Name == "local" {
+			cfg.Local = runagent.Bool(f.local)
+		}
+	})
+	return cfg, nil
+}
+
+// parseInterspersed parses flags that may appear before or after the
+// positional arguments, returning the positionals.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, &usageError{msg: err.Error()}
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// buildInput merges the --input JSON file with key=value arguments. Values
+// that parse as JSON keep their type; anything else is passed as a string.
+// A JSON array in the file becomes positional arguments.
+func buildInput(inputPath string, pairs []string) (runagent.RunInput, error) {
+	input := runagent.RunInput{InputKwargs: map[string]interface{}{}}
+
+	if inputPath != "" {
+		var data []byte
+		var err error
+		if inputPath == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(inputPath)
+		}
+		if err != nil {
+			return input, usagef("failed to read input: %v", err)
+		}
+
+		var decoded interface{}
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			return input, usagef("input %s is not valid JSON: %v", inputPath, err)
+		}
+		switch t := decoded.(type) {
+		case map[string]interface{}:
+			input.InputKwargs = t
+		case []interface{}:
+			input.InputArgs = t
+		default:
+			input.InputArgs = []interface{}{t}
+		}
+	}
+
+	for _, pair := range pairs {
+		key, raw, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return input, usagef("argument %q is not key=value", pair)
+		}
+		var value interface{}
+		if err := json.Unmarshal([]byte(raw), &value); err != nil {
+			value = raw
+		}
+		input.InputKwargs[key] = value
+	}
+	return input, nil
+}
+
+// signalContext cancels on Ctrl-C so streams and runs stop promptly.
+func signalContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt)
+}
+
+func cmdRun(args []string) error {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	var cf clientFlags
+	cf.register(fs, true)
+	inputPath := fs.String("input", "", "JSON file with kwargs (object) or args (array); - for stdin")
+	format := outputFlag(fs, formatJSON)
+
+	pairs, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+	if cf.tag == "" {
+		return usagef("--tag is required")
+	}
+	input, err := buildInput(*inputPath, pairs)
+	if err != nil {
+		return err
+	}
+	client, err := cf.client(fs, cf.tag)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	started := time.Now()
+	result, err := client.RunWithResult(ctx, input)
+	recordRun(client, input, result, err, started)
+	if err != nil {
+		return err
+	}
+	return printValue(result.Output, *format)
+}
+
+func cmdStream(args []string) error {
+	fs := flag.NewFlagSet("stream", flag.ContinueOnError)
+	var cf clientFlags
+	cf.register(fs, true)
+	inputPath := fs.String("input", "", "JSON file with kwargs (object) or args (array); - for stdin")
+	format := outputFlag(fs, formatTable)
+
+	pairs, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+	if cf.tag == "" {
+		return usagef("--tag is required")
+	}
+	input, err := buildInput(*inputPath, pairs)
+	if err != nil {
+		return err
+	}
+	client, err := cf.client(fs, cf.tag)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	started := time.Now()
+	stream, err := client.RunStream(ctx, input)
+	if err != nil {
+		recordRun(client, input, nil, err, started)
+		return err
+	}
+	defer stream.Close()
+
+	var chunks []interface{}
+	for {
+		event, more, err := stream.NextEvent(ctx)
+		if err != nil {
+			recordRun(client, input, nil, err, started)
+			return err
+		}
+		if event != nil {
+			printStreamEvent(event, *format)
+			if event.Kind == runagent.StreamEventData {
+				chunks = append(chunks, event.Payload)
+			}
+		}
+		if !more {
+			break
+		}
+	}
+	if *format == formatTable {
+		fmt.Println()
+	}
+
+	recordRun(client, input, &runagent.RunResult{Output: joinChunks(chunks)}, nil, started)
+	return nil
+}
+
+// joinChunks concatenates string chunks and keeps anything else as a list,
+// so a streamed run is recorded like the equivalent non-streamed one.
+func joinChunks(chunks []interface{}) interface{} {
+	var sb strings.Builder
+	for _, chunk := range chunks {
+		str, ok := chunk.(string)
+		if !ok {
+			return chunks
+		}
+		sb.WriteString(str)
+	}
+	return sb.String()
+}
+
+// printStreamEvent prints tokens as they arrive in table mode and one JSON
+// object per event in JSON mode.
+func printStreamEvent(event *runagent.StreamEvent, format string) {
+	if format == formatJSON {
+		line, _ := json.Marshal(map[string]interface{}{
+			"kind":    event.Kind,
+			"type":    event.Type,
+			"status":  event.Status,
+			"payload": event.Payload,
+		})
+		fmt.Println(string(line))
+		return
+	}
+	if event.Kind != runagent.StreamEventData {
+		return
+	}
+	if text, ok := event.Payload.(string); ok {
+		fmt.Print(text)
+		return
+	}
+	fmt.Println(cell(event.Payload))
+}
+
+// recordRun appends a local run to the agent's history. Only agents in the
+// local registry keep a history; remote runs are not recorded. History is
+// best effort: failures are reported on stderr and never fail the command.
+func recordRun(client *runagent.RunAgentClient, input runagent.RunInput, result *runagent.RunResult, runErr error, started time.Time) {
+	if !client.Local() {
+		return
+	}
+	agentID := client.AgentID()
+
+	svc, err := db.NewService("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to record run: %v\n", err)
+		return
+	}
+	defer svc.Close()
+
+	agent, err := svc.GetAgent(agentID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to record run: %v\n", err)
+		return
+	}
+	if agent == nil {
+		return
+	}
+
+	inputData, _ := json.Marshal(map[string]interface{}{
+		"input_args":   input.InputArgs,
+		"input_kwargs": input.InputKwargs,
+	})
+	completed := time.Now()
+	elapsed := completed.Sub(started).Seconds()
+	run := &db.AgentRun{
+		AgentID:       agentID,
+		InputData:     string(inputData),
+		Success:       runErr == nil,
+		ExecutionTime: &elapsed,
+		StartedAt:     started,
+		CompletedAt:   &completed,
+	}
+	if runErr != nil {
+		message := runErr.Error()
+		run.ErrorMessage = &message
+	} else if result != nil {
+		if output, err := json.Marshal(result.Output); err == nil {
+			text := string(output)
+			run.OutputData = &text
+		}
+	}
+	if err := svc.RecordAgentRun(run); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to record run: %v\n", err)
+	}
+}
+
+func cmdArch(args []string) error {
+	fs := flag.NewFlagSet("arch", flag.ContinueOnError)
+	var cf clientFlags
+	cf.register(fs, false)
+	format := outputFlag(fs, formatTable)
+
+	if _, err := parseInterspersed(fs, args); err != nil {
+		return err
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+	// Any non-stream tag works; architecture is per agent.
+	client, err := cf.client(fs, "generic")
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	arch, err := client.GetArchitecture(ctx)
+	if err != nil {
+		return err
+	}
+	if *format == formatJSON {
+		return printJSON(arch)
+	}
+
+	rows := make([][]string, 0, len(arch.Entrypoints))
+	for _, ep := range arch.Entrypoints {
+		rows = append(rows, []string{ep.Tag, orDash(ep.File), orDash(ep.Module), orDash(ep.Description)})
+	}
+	return printTable([]string{"tag", "file", "module", "description"}, rows)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
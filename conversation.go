@@ -0,0 +1,355 @@
+package runagent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/runagent-dev/runagent-go/internal/db"
+	"github.com/runagent-dev/runagent-go/internal/utils"
+)
+
+// Transcript roles recorded by Conversation.
+const (
+	TurnRoleUser  = "user"
+	TurnRoleAgent = "agent"
+)
+
+// ConversationOptions customize a Conversation.
+type ConversationOptions struct {
+	// ThreadID resumes or names a thread; a random ID is generated when empty.
+	ThreadID string
+	// KeepTranscript records inputs and outputs in memory.
+	KeepTranscript bool
+	// Persist stores the transcript in the local SQLite database so it can be
+	// listed, resumed, forked and deleted later. Implies KeepTranscript.
+	Persist bool
+}
+
+// ConversationTurn is a single transcript entry.
+type ConversationTurn struct {
+	Role      string      `json:"role"`
+	Content   interface{} `json:"content"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// ConversationInfo summarizes a persisted conversation.
+type ConversationInfo struct {
+	ThreadID       string
+	AgentID        string
+	ParentThreadID string
+	TurnCount      int
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Conversation carries a thread ID across runs so chat-style agents keep
+// their state server-side, and optionally keeps a local transcript.
+type Conversation struct {
+	client   *RunAgentClient
+	threadID string
+	keep     bool
+	persist  bool
+
+	mu         sync.Mutex
+	transcript []ConversationTurn
+}
+
+// NewConversation starts a conversation bound to this client's agent.
+func (c *RunAgentClient) NewConversation(opts ConversationOptions) (*Conversation, error) {
+	threadID := strings.TrimSpace(opts.ThreadID)
+	if threadID == "" {
+		threadID = utils.NewUUID()
+	}
+
+	conv := &Conversation{
+		client:   c,
+		threadID: threadID,
+		keep:     opts.KeepTranscript || opts.Persist,
+		persist:  opts.Persist,
+	}
+
+	if conv.persist {
+		err := withConversationStore(func(svc *db.Service) error {
+			return svc.CreateConversation(&db.Conversation{ThreadID: threadID, AgentID: c.agentID})
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return conv, nil
+}
+
+// ResumeConversation reopens a persisted conversation and loads its transcript.
+func (c *RunAgentClient) ResumeConversation(threadID string) (*Conversation, error) {
+	var stored *db.Conversation
+	var messages []*db.ConversationMessage
+	err := withConversationStore(func(svc *db.Service) error {
+		var err error
+		if stored, err = svc.GetConversation(threadID); err != nil || stored == nil {
+			return err
+		}
+		messages, err = svc.GetConversationMessages(threadID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		return nil, newError(
+			ErrorTypeValidation,
+			fmt.Sprintf("conversation %s was not found locally", threadID),
+			withCode("CONVERSATION_NOT_FOUND"),
+			withSuggestion("Use ListConversations to see persisted threads"),
+		)
+	}
+	if stored.AgentID != c.agentID {
+		return nil, newError(
+			ErrorTypeValidation,
+			fmt.Sprintf("conversation %s belongs to agent %s", threadID, stored.AgentID),
+			withCode("CONVERSATION_AGENT_MISMATCH"),
+		)
+	}
+
+	transcript := make([]ConversationTurn, 0, len(messages))
+	for _, msg := range messages {
+		transcript = append(transcript, ConversationTurn{
+			Role:      msg.Role,
+			Content:   decodeStructuredString(msg.Content),
+			CreatedAt: msg.CreatedAt,
+		})
+	}
+
+	return &Conversation{
+		client:     c,
+		threadID:   threadID,
+		keep:       true,
+		persist:    true,
+		transcript: transcript,
+	}, nil
+}
+
+// ListConversations returns persisted conversations for this client's agent.
+func (c *RunAgentClient) ListConversations() ([]ConversationInfo, error) {
+	var stored []*db.Conversation
+	err := withConversationStore(func(svc *db.Service) error {
+		var err error
+		stored, err = svc.ListConversations(c.agentID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]ConversationInfo, 0, len(stored))
+	for _, conv := range stored {
+		infos = append(infos, conversationInfoFromDB(conv))
+	}
+	return infos, nil
+}
+
+// DeleteConversation removes a persisted conversation and its transcript.
+func (c *RunAgentClient) DeleteConversation(threadID string) error {
+	return withConversationStore(func(svc *db.Service) error {
+		return svc.DeleteConversation(threadID)
+	})
+}
+
+// ThreadID returns the conversation's thread identifier.
+func (conv *Conversation) ThreadID() string {
+	return conv.threadID
+}
+
+// Transcript returns a copy of the recorded turns.
+func (conv *Conversation) Transcript() []ConversationTurn {
+	conv.mu.Lock()
+	defer conv.mu.Unlock()
+	out := make([]ConversationTurn, len(conv.transcript))
+	copy(out, conv.transcript)
+	return out
+}
+
+// Run invokes the agent within this conversation's thread. The input and
+// output are recorded together once the run succeeds, so a failed run leaves
+// no turn behind.
+func (conv *Conversation) Run(ctx context.Context, values ...any) (interface{}, error) {
+	input, err := conv.prepare(values...)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := conv.client.Run(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if err := conv.recordExchange(input, result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// RunStream starts a streaming execution within this conversation's thread.
+// The input and the streamed data chunks, joined into a single agent turn,
+// are recorded once the stream completes; a stream that fails or is closed
+// early records nothing. A failure to record is returned from NextEvent.
+func (conv *Conversation) RunStream(ctx context.Context, values ...any) (*StreamIterator, error) {
+	input, err := conv.prepare(values...)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := conv.client.RunStream(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if conv.keep {
+		var chunks []interface{}
+		stream.onEvent = func(event *StreamEvent) error {
+			switch event.Kind {
+			case StreamEventData:
+				chunks = append(chunks, event.Payload)
+			case StreamEventCompleted:
+				return conv.recordExchange(input, joinStreamChunks(chunks))
+			}
+			return nil
+		}
+	}
+	return stream, nil
+}
+
+// Fork copies the conversation under a new thread ID. Persisted
+// conversations are forked in the local database as well. Only the local
+// transcript is copied; server-side thread state starts fresh.
+func (conv *Conversation) Fork() (*Conversation, error) {
+	forked := &Conversation{
+		client:     conv.client,
+		threadID:   utils.NewUUID(),
+		keep:       conv.keep,
+		persist:    conv.persist,
+		transcript: conv.Transcript(),
+	}
+
+	if conv.persist {
+		err := withConversationStore(func(svc *db.Service) error {
+			_, err := svc.ForkConversation(conv.threadID, forked.threadID)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return forked, nil
+}
+
+// Delete removes the local transcript, including the persisted copy.
+func (conv *Conversation) Delete() error {
+	conv.mu.Lock()
+	conv.transcript = nil
+	conv.mu.Unlock()
+
+	if !conv.persist {
+		return nil
+	}
+	return conv.client.DeleteConversation(conv.threadID)
+}
+
+func (conv *Conversation) prepare(values ...any) (RunInput, error) {
+	input, err := coerceToRunInput(values...)
+	if err != nil {
+		return RunInput{}, err
+	}
+	// Shape messages first so the transcript records what is sent.
+	if input, err = conv.client.adapter.AdaptInput(input); err != nil {
+		return RunInput{}, err
+	}
+	input.ThreadID = conv.threadID
+	return input, nil
+}
+
+// recordExchange records a successful run's input and output turns. Both
+// are persisted in one transaction and only then added to the in-memory
+// transcript, so the two never disagree.
+func (conv *Conversation) recordExchange(input RunInput, output interface{}) error {
+	if !conv.keep {
+		return nil
+	}
+
+	now := time.Now()
+	turns := []ConversationTurn{
+		{Role: TurnRoleUser, Content: map[string]interface{}{
+			"input_args":   input.InputArgs,
+			"input_kwargs": input.InputKwargs,
+		}, CreatedAt: now},
+		{Role: TurnRoleAgent, Content: output, CreatedAt: now},
+	}
+
+	if conv.persist {
+		msgs := make([]*db.ConversationMessage, len(turns))
+		for i, turn := range turns {
+			encoded, err := json.Marshal(turn.Content)
+			if err != nil {
+				return newError(ErrorTypeValidation, "failed to serialize conversation turn", withCause(err))
+			}
+			msgs[i] = &db.ConversationMessage{
+				ThreadID:  conv.threadID,
+				Role:      turn.Role,
+				Content:   string(encoded),
+				CreatedAt: turn.CreatedAt,
+			}
+		}
+		err := withConversationStore(func(svc *db.Service) error {
+			return svc.AddConversationMessages(msgs...)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	conv.mu.Lock()
+	conv.transcript = append(conv.transcript, turns...)
+	conv.mu.Unlock()
+	return nil
+}
+
+// joinStreamChunks concatenates string chunks and keeps anything else as a list.
+func joinStreamChunks(chunks []interface{}) interface{} {
+	var sb strings.Builder
+	for _, chunk := range chunks {
+		str, ok := chunk.(string)
+		if !ok {
+			return chunks
+		}
+		sb.WriteString(str)
+	}
+	return sb.String()
+}
+
+func withConversationStore(fn func(*db.Service) error) error {
+	svc, err := db.NewService("")
+	if err != nil {
+		return newError(ErrorTypeConnection, "failed to open local conversation store", withCause(err))
+	}
+	defer svc.Close()
+
+	if err := fn(svc); err != nil {
+		return newError(ErrorTypeServer, "local conversation store operation failed", withCause(err))
+	}
+	return nil
+}
+
+func conversationInfoFromDB(conv *db.Conversation) ConversationInfo {
+	info := ConversationInfo{
+		ThreadID:  conv.ThreadID,
+		AgentID:   conv.AgentID,
+		TurnCount: conv.MessageCount,
+		CreatedAt: conv.CreatedAt,
+		UpdatedAt: conv.UpdatedAt,
+	}
+	if conv.ParentThreadID != nil {
+		info.ParentThreadID = *conv.ParentThreadID
+	}
+	return info
+}
@@ -0,0 +1,255 @@
+package runagent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/runagent-dev/runagent-go/internal/constants"
+)
+
+// CredentialProvider supplies the API key for remote calls. The client asks
+// for the key on every request, so providers may rotate keys at any time.
+type CredentialProvider interface {
+	APIKey(ctx context.Context) (string, error)
+}
+
+// CredentialInvalidator is implemented by providers that cache keys. After a
+// 401 the client calls Invalidate and re-fetches the key, retrying once when
+// it changed.
+type CredentialInvalidator interface {
+	Invalidate()
+}
+
+type staticCredentials string
+
+// StaticCredentials always returns key.
+func StaticCredentials(key string) CredentialProvider {
+	return staticCredentials(strings.TrimSpace(key))
+}
+
+func (s staticCredentials) APIKey(context.Context) (string, error) {
+	return string(s), nil
+}
+
+type envCredentials string
+
+// EnvCredentials reads the key from the named environment variable on every
+// call; an empty name uses RUNAGENT_API_KEY.
+func EnvCredentials(name string) CredentialProvider {
+	if name == "" {
+		name = constants.EnvAPIKey
+	}
+	return envCredentials(name)
+}
+
+func (e envCredentials) APIKey(context.Context) (string, error) {
+	return strings.TrimSpace(os.Getenv(string(e))), nil
+}
+
+// FileCredentialProvider reads the key from a file and re-reads it whenever
+// the file changes.
+type FileCredentialProvider struct {
+	path string
+
+	mu      sync.Mutex
+	key     string
+	modTime time.Time
+	size    int64
+}
+
+// FileCredentials creates a provider for the key stored at path.
+func FileCredentials(path string) *FileCredentialProvider {
+	return &FileCredentialProvider{path: path}
+}
+
+// APIKey implements CredentialProvider.
+func (f *FileCredentialProvider) APIKey(context.Context) (string, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return "", newError(ErrorTypeAuthentication, "failed to read credentials file", withCause(err))
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.key != "" && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return f.key, nil
+	}
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return "", newError(ErrorTypeAuthentication, "failed to read credentials file", withCause(err))
+	}
+	f.key = strings.TrimSpace(string(data))
+	f.modTime = info.ModTime()
+	f.size = info.Size()
+	return f.key, nil
+}
+
+// Invalidate implements CredentialInvalidator.
+func (f *FileCredentialProvider) Invalidate() {
+	f.mu.Lock()
+	f.key = ""
+	f.mu.Unlock()
+}
+
+// CommandCredentialProvider obtains the key from an external helper command,
+// in the spirit of git credential helpers. The key is cached until
+// invalidated.
+type CommandCredentialProvider struct {
+	name string
+	args []string
+
+	mu  sync.Mutex
+	key string
+}
+
+// CommandCredentials creates a provider that runs name with args. The helper
+// may print the bare key, or key=value lines containing api_key= or
+// password=.
+func CommandCredentials(name string, args ...string) *CommandCredentialProvider {
+	return &CommandCredentialProvider{name: name, args: args}
+}
+
+// APIKey implements CredentialProvider.
+func (p *CommandCredentialProvider) APIKey(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.key != "" {
+		return p.key, nil
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.name, p.args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", newError(
+			ErrorTypeAuthentication,
+			fmt.Sprintf("credential helper %s failed", p.name),
+			withCause(err),
+			withDetails(map[string]interface{}{"stderr": strings.TrimSpace(stderr.String())}),
+		)
+	}
+	p.key = parseHelperOutput(stdout.String())
+	return p.key, nil
+}
+
+// Invalidate implements CredentialInvalidator.
+func (p *CommandCredentialProvider) Invalidate() {
+	p.mu.Lock()
+	p.key = ""
+	p.mu.Unlock()
+}
+
+func parseHelperOutput(output string) string {
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if ok && (key == "api_key" || key == "password") {
+			return strings.TrimSpace(value)
+		}
+	}
+	return strings.TrimSpace(output)
+}
+
+type chainCredentials []CredentialProvider
+
+// ChainCredentials returns the first non-empty key from providers, in order.
+// Provider errors are skipped unless no provider yields a key.
+func ChainCredentials(providers ...CredentialProvider) CredentialProvider {
+	return chainCredentials(providers)
+}
+
+func (c chainCredentials) APIKey(ctx context.Context) (string, error) {
+	var lastErr error
+	for _, provider := range c {
+		key, err := provider.APIKey(ctx)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if key != "" {
+			return key, nil
+		}
+	}
+	return "", lastErr
+}
+
+// Invalidate implements CredentialInvalidator.
+func (c chainCredentials) Invalidate() {
+	for _, provider := range c {
+		if inv, ok := provider.(CredentialInvalidator); ok {
+			inv.Invalidate()
+		}
+	}
+}
+
+// currentAPIKey resolves the key for a remote call.
+func (c *RunAgentClient) currentAPIKey(ctx context.Context) (string, error) {
+	key, err := c.credentials.APIKey(ctx)
+	if err != nil {
+		return "", err
+	}
+	if key == "" {
+		return "", newError(
+			ErrorTypeAuthentication,
+			"api_key is required for remote calls",
+			withSuggestion("Set RUNAGENT_API_KEY, pass Config.APIKey or configure Config.Credentials"),
+		)
+	}
+	return key, nil
+}
+
+// refreshAPIKey invalidates cached credentials after a 401 and reports the
+// new key when it differs from the rejected one.
+func (c *RunAgentClient) refreshAPIKey(ctx context.Context, rejected string) (string, bool) {
+	inv, ok := c.credentials.(CredentialInvalidator)
+	if !ok {
+		return "", false
+	}
+	inv.Invalidate()
+	key, err := c.credentials.APIKey(ctx)
+	if err != nil || key == "" || key == rejected {
+		return "", false
+	}
+	return key, true
+}
+
+// doAuthorized sends req and, when a remote call is rejected with 401,
+// refreshes the credentials and retries once with the new key.
+func (c *RunAgentClient) doAuthorized(client *http.Client, req *http.Request) (*http.Response, error) {
+	resp, err := client.Do(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	auth := req.Header.Get("Authorization")
+	// Streaming bodies such as multipart uploads cannot be replayed.
+	if c.local || auth == "" || (req.Body != nil && req.GetBody == nil) {
+		return resp, nil
+	}
+	key, ok := c.refreshAPIKey(req.Context(), strings.TrimPrefix(auth, "Bearer "))
+	if !ok {
+		return resp, nil
+	}
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+		retry.Body = body
+	}
+	resp.Body.Close()
+	retry.Header.Set("Authorization", fmt.Sprintf("Bearer %s", key))
+	return client.Do(retry)
+}
@@ -0,0 +1,454 @@
+package runagent
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/runagent-dev/runagent-go/internal/config"
+	"github.com/runagent-dev/runagent-go/internal/constants"
+	"github.com/runagent-dev/runagent-go/internal/utils"
+)
+
+// CheckStatus is the outcome of a single diagnostic check.
+type CheckStatus string
+
+const (
+	CheckPass CheckStatus = "pass"
+	CheckWarn CheckStatus = "warn"
+	CheckFail CheckStatus = "fail"
+	CheckSkip CheckStatus = "skip"
+)
+
+// ConfigSource names where an effective configuration value came from.
+type ConfigSource string
+
+const (
+	SourceExplicit ConfigSource = "explicit"
+	SourceEnv      ConfigSource = "env"
+	SourceProfile  ConfigSource = "profile"
+	SourceRegistry ConfigSource = "registry"
+	SourceDefault  ConfigSource = "default"
+)
+
+// ConfigValue is one resolved setting. Secrets are masked.
+type ConfigValue struct {
+	Name   string       `json:"name"`
+	Value  string       `json:"value"`
+	Source ConfigSource `json:"source"`
+}
+
+// DiagnosticCheck is the result of one step of Diagnose.
+type DiagnosticCheck struct {
+	Name       string        `json:"name"`
+	Status     CheckStatus   `json:"status"`
+	Message    string        `json:"message"`
+	Suggestion string        `json:"suggestion,omitempty"`
+	Duration   time.Duration `json:"duration"`
+	Err        error         `json:"-"`
+}
+
+// DiagnosticReport collects the effective configuration and check results.
+type DiagnosticReport struct {
+	AgentID       string            `json:"agent_id"`
+	EntrypointTag string            `json:"entrypoint_tag"`
+	Local         bool              `json:"local"`
+	Config        []ConfigValue     `json:"config"`
+	Checks        []DiagnosticCheck `json:"checks"`
+}
+
+// OK reports whether no check failed. Warnings do not count as failures.
+func (r *DiagnosticReport) OK() bool {
+	for _, check := range r.Checks {
+		if check.Status == CheckFail {
+			return false
+		}
+	}
+	return true
+}
+
+// Err returns the error of the first failed check, or nil.
+func (r *DiagnosticReport) Err() error {
+	for _, check := range r.Checks {
+		if check.Status == CheckFail {
+			if check.Err != nil {
+				return check.Err
+			}
+			return newError(ErrorTypeUnknown, check.Message)
+		}
+	}
+	return nil
+}
+
+// Suggestions returns the suggestions of failed and warned checks in order.
+func (r *DiagnosticReport) Suggestions() []string {
+	var out []string
+	for _, check := range r.Checks {
+		if check.Suggestion != "" && (check.Status == CheckFail || check.Status == CheckWarn) {
+			out = append(out, fmt.Sprintf("%s: %s", check.Name, check.Suggestion))
+		}
+	}
+	return out
+}
+
+func (r *DiagnosticReport) addConfig(name, value string, source ConfigSource) {
+	r.Config = append(r.Config, ConfigValue{Name: name, Value: value, Source: source})
+}
+
+func (r *DiagnosticReport) addCheck(name string, started time.Time, status CheckStatus, message, suggestion string, err error) {
+	// Messages and suggestions default to the error's own, kept apart so
+	// the suggestion is not repeated in the message.
+	if runErr, ok := AsRunAgentError(err); ok {
+		if message == "" {
+			message = fmt.Sprintf("%s: %s", runErr.Type, runErr.Message)
+		}
+		if suggestion == "" {
+			suggestion = runErr.Suggestion
+		}
+	} else if message == "" && err != nil {
+		message = err.Error()
+	}
+	r.Checks = append(r.Checks, DiagnosticCheck{
+		Name:       name,
+		Status:     status,
+		Message:    message,
+		Suggestion: suggestion,
+		Duration:   time.Since(started),
+		Err:        err,
+	})
+}
+
+// Diagnose walks the same resolution path as NewRunAgentClient and probes
+// the agent: profile and registry lookup, credentials, /health, the
+// architecture and entrypoint tag, and the WebSocket upgrade. It never
+// returns an error; failures are recorded as checks in the report. An empty
+// EntrypointTag skips the entrypoint check.
+func Diagnose(ctx context.Context, cfg Config) *DiagnosticReport {
+	report := &DiagnosticReport{AgentID: cfg.AgentID, EntrypointTag: cfg.EntrypointTag}
+
+	started := time.Now()
+	if strings.TrimSpace(cfg.AgentID) == "" {
+		report.addCheck("config", started, CheckFail, "agent_id is required", "Pass the agent ID to diagnose", newError(ErrorTypeValidation, "agent_id is required"))
+		return report
+	}
+
+	resolved, err := resolveConfig(cfg)
+	if err != nil {
+		report.addCheck("profile", started, CheckFail, "", "", err)
+		return report
+	}
+	switch {
+	case resolved.profileName != "":
+		report.addCheck("profile", started, CheckPass, fmt.Sprintf("using profile %q", resolved.profileName), "", nil)
+	case *resolved.profile != (config.Profile{}):
+		report.addCheck("profile", started, CheckPass, "using the default profile from the config file", "", nil)
+	default:
+		report.addCheck("profile", started, CheckSkip, "no profile configured", "", nil)
+	}
+
+	report.Local = resolved.local
+	proceed := !report.Local || report.checkRegistry(cfg, resolved)
+	report.recordConfig(cfg, resolved)
+	if !proceed {
+		return report
+	}
+
+	// Build the client exactly as callers would. Architecture is per agent,
+	// so any non-stream tag works when none was given.
+	clientCfg := cfg
+	if strings.TrimSpace(clientCfg.EntrypointTag) == "" {
+		clientCfg.EntrypointTag = "generic"
+	}
+	started = time.Now()
+	client, err := NewRunAgentClient(clientCfg)
+	if err != nil {
+		report.addCheck("client", started, CheckFail, "", "", err)
+		return report
+	}
+	report.addCheck("client", started, CheckPass, fmt.Sprintf("resolved %s", client.baseRESTURL), "", nil)
+
+	if !report.Local {
+		started = time.Now()
+		if _, err := client.currentAPIKey(ctx); err != nil {
+			report.addCheck("credentials", started, CheckFail, "", "", err)
+			return report
+		}
+		report.addCheck("credentials", started, CheckPass, "API key available", "", nil)
+	}
+
+	if !report.checkHealth(ctx, client) {
+		return report
+	}
+	report.checkArchitecture(ctx, client, cfg.EntrypointTag)
+	report.checkWebSocket(ctx, client)
+	return report
+}
+
+// recordConfig records each effective value and where it came from, as
+// resolved for NewRunAgentClient.
+func (r *DiagnosticReport) recordConfig(cfg Config, resolved *resolvedConfig) {
+	r.addConfig("agent_id", cfg.AgentID, SourceExplicit)
+	if cfg.EntrypointTag != "" {
+		r.addConfig("entrypoint_tag", cfg.EntrypointTag, SourceExplicit)
+	}
+	if resolved.profileName != "" {
+		r.addConfig("profile", resolved.profileName, resolved.profileSource)
+	}
+	r.addConfig("local", strconv.FormatBool(resolved.local), resolved.localSource)
+	r.addConfig("timeout_seconds", strconv.Itoa(resolved.timeout), resolved.timeoutSource)
+
+	if resolved.local {
+		if resolved.socketPath != "" {
+			r.addConfig("socket_path", resolved.socketPath, resolved.socketPathSource)
+		}
+		if resolved.host != "" {
+			r.addConfig("host", resolved.host, resolved.hostSource)
+		}
+		if resolved.port != 0 {
+			r.addConfig("port", strconv.Itoa(resolved.port), resolved.portSource)
+		}
+	} else {
+		r.addConfig("base_url", resolved.baseURL, resolved.baseURLSource)
+		switch {
+		case resolved.apiKey != "":
+			r.addConfig("api_key", utils.MaskSecret(resolved.apiKey), resolved.apiKeySource)
+		case cfg.Credentials != nil && resolved.apiKeySource == SourceExplicit:
+			r.addConfig("api_key", fmt.Sprintf("%T", resolved.credentials), SourceExplicit)
+		default:
+			r.addConfig("api_key", "(unset)", SourceDefault)
+		}
+	}
+
+	if resolved.transport == "" {
+		r.addConfig("stream_transport", string(StreamTransportAuto), SourceDefault)
+	} else {
+		r.addConfig("stream_transport", resolved.transport, resolved.transportSource)
+	}
+	if resolved.tls != nil {
+		r.addConfig("tls", describeTLS(resolved.tls), resolved.tlsSource)
+	}
+	if cfg.Proxy != "" {
+		r.addConfig("proxy", cfg.Proxy, SourceExplicit)
+	}
+	if resolved.framework != "" {
+		r.addConfig("framework", string(resolved.framework), resolved.frameworkSource)
+	}
+}
+
+// checkRegistry verifies the local database and the agent row, and fills
+// registry-derived settings into resolved. A missing row is only fatal when
+// the client would need it to resolve the address; it reports whether to
+// continue.
+func (r *DiagnosticReport) checkRegistry(cfg Config, resolved *resolvedConfig) bool {
+	needed := resolved.needsRegistry()
+	missing := CheckWarn
+	if needed {
+		missing = CheckFail
+	}
+
+	started := time.Now()
+	dbPath := constants.GetDatabasePath()
+	// Stat first: opening the registry would create an empty database.
+	if _, err := os.Stat(dbPath); err != nil {
+		regErr := newError(ErrorTypeValidation, fmt.Sprintf("local registry %s not found", dbPath), withCause(err))
+		r.addCheck("registry", started, missing, regErr.Message,
+			fmt.Sprintf("Start the agent locally or set %s to the directory holding %s", constants.EnvCacheDir, constants.DatabaseFileName), regErr)
+		return !needed
+	}
+
+	agent, err := discoverLocalAgent(cfg.AgentID)
+	if err != nil {
+		status := missing
+		if runErr, ok := AsRunAgentError(err); ok && runErr.Type != ErrorTypeValidation {
+			status = CheckFail
+		}
+		r.addCheck("registry", started, status, "", "", err)
+		return status != CheckFail
+	}
+	r.addCheck("registry", started, CheckPass, fmt.Sprintf("agent %s found in %s", agent.AgentID, dbPath), "", nil)
+	r.checkAgentConfig(agent.AgentPath, cfg.EntrypointTag)
+
+	if needed {
+		resolved.applyRegistry(agent)
+	}
+	return true
+}
+
+// checkAgentConfig reads the registered agent's runagent.config.json and
+// checks the tag against its entrypoints. An unreadable file only warns.
+func (r *DiagnosticReport) checkAgentConfig(agentPath, tag string) {
+	started := time.Now()
+	if agentPath == "" {
+		r.addCheck("agent_config", started, CheckSkip, "registry has no agent path", "", nil)
+		return
+	}
+	agentCfg, err := LoadAgentConfig(agentPath)
+	if err != nil {
+		r.addCheck("agent_config", started, CheckWarn, "", "", err)
+		return
+	}
+	r.addConfig("agent_config", agentCfg.Path, SourceRegistry)
+	if strings.TrimSpace(tag) != "" {
+		if err := agentCfg.CheckEntrypoint(tag); err != nil {
+			r.addCheck("agent_config", started, CheckFail, "", "", err)
+			return
+		}
+	}
+	tags := make([]string, len(agentCfg.AgentArchitecture.Entrypoints))
+	for i, ep := range agentCfg.AgentArchitecture.Entrypoints {
+		tags[i] = ep.Tag
+	}
+	r.addCheck("agent_config", started, CheckPass,
+		fmt.Sprintf("%s agent, %d entrypoints: %s", firstNonEmpty(agentCfg.Framework, "unknown"), len(tags), strings.Join(tags, ", ")), "", nil)
+}
+
+// checkHealth probes /health and reports whether later probes are worth
+// running. A 404 only warns since not every deployment exposes it.
+func (r *DiagnosticReport) checkHealth(ctx context.Context, c *RunAgentClient) bool {
+	started := time.Now()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseRESTURL+"/health", nil)
+	if err != nil {
+		r.addCheck("health", started, CheckFail, "", "", err)
+		return false
+	}
+	req.Header.Set("User-Agent", userAgent())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		wrapped := newError(ErrorTypeConnection, "failed to reach RunAgent service", withCause(err))
+		r.addCheck("health", started, CheckFail, fmt.Sprintf("%s: %v", wrapped.Message, err), networkSuggestion(err, c.local), wrapped)
+		return false
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		r.addCheck("health", started, CheckPass, fmt.Sprintf("%s responded 200", req.URL.Host), "", nil)
+	case resp.StatusCode == http.StatusNotFound:
+		r.addCheck("health", started, CheckWarn, "health endpoint not found; the service is reachable", "", nil)
+	default:
+		err := translateHTTPError(resp.StatusCode, resp.Header, body)
+		r.addCheck("health", started, CheckFail, "", "", err)
+	}
+	return true
+}
+
+// checkArchitecture fetches the architecture, bypassing the cache, and
+// verifies the entrypoint tag is deployed.
+func (r *DiagnosticReport) checkArchitecture(ctx context.Context, c *RunAgentClient, tag string) {
+	started := time.Now()
+	arch, err := c.fetchArchitecture(ctx)
+	if err != nil {
+		r.addCheck("architecture", started, CheckFail, "", "", err)
+		return
+	}
+	tags := make([]string, 0, len(arch.Entrypoints))
+	for _, ep := range arch.Entrypoints {
+		tags = append(tags, ep.Tag)
+	}
+	r.addCheck("architecture", started, CheckPass, fmt.Sprintf("%d entrypoints: %s", len(tags), strings.Join(tags, ", ")), "", nil)
+
+	started = time.Now()
+	if strings.TrimSpace(tag) == "" {
+		r.addCheck("entrypoint", started, CheckSkip, "no entrypoint tag given", "", nil)
+		return
+	}
+	for _, candidate := range tags {
+		if candidate == tag {
+			r.addCheck("entrypoint", started, CheckPass, fmt.Sprintf("entrypoint %s is deployed", tag), "", nil)
+			return
+		}
+	}
+	err = newError(
+		ErrorTypeValidation,
+		fmt.Sprintf("entrypoint %s is not deployed", tag),
+		withCode("ENTRYPOINT_NOT_FOUND"),
+		withSuggestion(fmt.Sprintf("Use one of: %s", strings.Join(tags, ", "))),
+	)
+	r.addCheck("entrypoint", started, CheckFail, "", "", err)
+}
+
+// checkWebSocket tests the run-stream upgrade without starting a run. The
+// failure only warns when RunStream can fall back to an HTTP transport.
+func (r *DiagnosticReport) checkWebSocket(ctx context.Context, c *RunAgentClient) {
+	started := time.Now()
+	usesWebSocket := false
+	for _, transport := range c.streamTransports {
+		if transport == StreamTransportWebSocket {
+			usesWebSocket = true
+		}
+	}
+	if !usesWebSocket {
+		r.addCheck("websocket", started, CheckSkip, "WebSocket transport disabled", "", nil)
+		return
+	}
+
+	conn, err := c.upgradeWebSocket(ctx, http.Header{"User-Agent": []string{userAgent()}})
+	if err != nil {
+		status := CheckFail
+		suggestion := ""
+		if len(c.streamTransports) > 1 && (isTransportFallbackError(err) || isRejectedHandshake(err)) {
+			status = CheckWarn
+			suggestion = "RunStream will fall back to SSE/NDJSON; set StreamTransport to skip the WebSocket attempt"
+		}
+		r.addCheck("websocket", started, status, err.Error(), suggestion, err)
+		return
+	}
+	conn.Close()
+	r.addCheck("websocket", started, CheckPass, "WebSocket upgrade succeeded", "", nil)
+}
+
+// networkSuggestion turns common dial failures into a next step.
+func networkSuggestion(err error, local bool) string {
+	var dnsErr *net.DNSError
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var certErr *x509.CertificateInvalidError
+	switch {
+	case errors.As(err, &dnsErr):
+		return "Check Config.BaseURL / RUNAGENT_BASE_URL; the host does not resolve"
+	case errors.As(err, &unknownAuthority):
+		return "Trust the server's CA with Config.TLS.CAFile or RUNAGENT_CA_FILE"
+	case errors.As(err, &hostnameErr):
+		return "The certificate does not match the host; set Config.TLS.ServerName"
+	case errors.As(err, &certErr):
+		return "The server certificate is invalid or expired"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "The request timed out; raise Config.TimeoutSeconds or check the network"
+	case local:
+		return "Start the agent locally and confirm the host/port or socket path"
+	default:
+		return "Check your network connection and proxy settings"
+	}
+}
+
+func describeTLS(cfg *TLSConfig) string {
+	var parts []string
+	if cfg.CAFile != "" {
+		parts = append(parts, "ca="+cfg.CAFile)
+	} else if len(cfg.CAPEM) > 0 {
+		parts = append(parts, "ca=<pem>")
+	}
+	if cfg.CertFile != "" {
+		parts = append(parts, "cert="+cfg.CertFile)
+	}
+	if cfg.ServerName != "" {
+		parts = append(parts, "server_name="+cfg.ServerName)
+	}
+	if cfg.InsecureSkipVerify {
+		parts = append(parts, "insecure")
+	}
+	if len(parts) == 0 {
+		return "default"
+	}
+	return strings.Join(parts, " ")
+}
@@ -1,6 +1,7 @@
 package runagent
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 )
@@ -25,6 +26,9 @@ type RunAgentError struct {
 	Suggestion string
 	Details    map[string]interface{}
 	Cause      error
+	// RequestID and RunID identify the failed request for support tickets.
+	RequestID string
+	RunID     string
 }
 
 func (e *RunAgentError) Error() string {
@@ -39,6 +43,9 @@ func (e *RunAgentError) Error() string {
 	if e.Suggestion != "" {
 		base = fmt.Sprintf("%s | suggestion: %s", base, e.Suggestion)
 	}
+	if e.RequestID != "" {
+		base = fmt.Sprintf("%s | request_id: %s", base, e.RequestID)
+	}
 	return base
 }
 
@@ -56,6 +63,20 @@ type RunAgentExecutionError struct {
 	HTTPStatus int
 }
 
+// AsRunAgentError extracts the *RunAgentError from err, including the one
+// embedded in a *RunAgentExecutionError.
+func AsRunAgentError(err error) (*RunAgentError, bool) {
+	var execErr *RunAgentExecutionError
+	if errors.As(err, &execErr) && execErr.RunAgentError != nil {
+		return execErr.RunAgentError, true
+	}
+	var runErr *RunAgentError
+	if errors.As(err, &runErr) {
+		return runErr, true
+	}
+	return nil, false
+}
+
 func newError(kind ErrorType, message string, opts ...func(*RunAgentError)) *RunAgentError {
 	err := &RunAgentError{
 		Type:    kind,
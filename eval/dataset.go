@@ -0,0 +1,127 @@
+// Package eval runs datasets of inputs against a RunAgent entrypoint, scores
+// the outputs and compares reports between deployments.
+//
+// Datasets are JSON lines, one case per line:
+//
+//	{"id": "capital-fr", "input": {"question": "Capital of France?"}, "expected": "Paris"}
+//	{"id": "sum", "input": [2, 3], "expected": 5, "scorer": "numeric"}
+//
+// An object input becomes keyword arguments, an array becomes positional
+// arguments and any other value a single positional argument. Objects with
+// "input_args" or "input_kwargs" keys set both explicitly.
+package eval
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/runagent-dev/runagent-go"
+)
+
+// Case is one dataset entry.
+type Case struct {
+	// ID identifies the case across runs; reports are compared by ID.
+	ID string `json:"id"`
+	// Input is the raw input as written in the dataset.
+	Input json.RawMessage `json:"input"`
+	// Expected is passed to the scorer along with the output.
+	Expected interface{} `json:"expected"`
+	// Scorer names the scorer for this case; empty uses Options.Scorer.
+	Scorer string `json:"scorer,omitempty"`
+	// Tags are free-form labels copied to the result.
+	Tags []string `json:"tags,omitempty"`
+}
+
+// RunInput converts the case input into the arguments for Run.
+func (c Case) RunInput() (runagent.RunInput, error) {
+	input := runagent.RunInput{}
+	raw := strings.TrimSpace(string(c.Input))
+	if raw == "" || raw == "null" {
+		return input, nil
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(c.Input, &decoded); err != nil {
+		return input, fmt.Errorf("case %s: invalid input: %w", c.ID, err)
+	}
+	switch t := decoded.(type) {
+	case map[string]interface{}:
+		args, hasArgs := t["input_args"]
+		kwargs, hasKwargs := t["input_kwargs"]
+		if !hasArgs && !hasKwargs {
+			input.InputKwargs = t
+			return input, nil
+		}
+		if hasArgs {
+			list, ok := args.([]interface{})
+			if !ok {
+				return input, fmt.Errorf("case %s: input_args must be an array", c.ID)
+			}
+			input.InputArgs = list
+		}
+		if hasKwargs {
+			m, ok := kwargs.(map[string]interface{})
+			if !ok {
+				return input, fmt.Errorf("case %s: input_kwargs must be an object", c.ID)
+			}
+			input.InputKwargs = m
+		}
+	case []interface{}:
+		input.InputArgs = t
+	default:
+		input.InputArgs = []interface{}{t}
+	}
+	return input, nil
+}
+
+// LoadDataset reads a JSONL dataset from path.
+func LoadDataset(path string) ([]Case, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadDataset(file)
+}
+
+// ReadDataset parses JSONL cases. Blank lines and lines starting with # are
+// skipped; cases without an ID are named after their line number.
+func ReadDataset(r io.Reader) ([]Case, error) {
+	var cases []Case
+	seen := map[string]int{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64<<20)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		var c Case
+		if err := json.Unmarshal([]byte(text), &c); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if c.ID == "" {
+			c.ID = fmt.Sprintf("line-%d", line)
+		}
+		if prev, ok := seen[c.ID]; ok {
+			return nil, fmt.Errorf("line %d: duplicate id %q (first on line %d)", line, c.ID, prev)
+		}
+		seen[c.ID] = line
+		if _, err := c.RunInput(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		cases = append(cases, c)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return cases, nil
+}
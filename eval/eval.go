@@ -0,0 +1,139 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/runagent-dev/runagent-go"
+)
+
+// Options control Run.
+type Options struct {
+	// Concurrency bounds in-flight runs; defaults to runagent.DefaultBatchConcurrency.
+	Concurrency int
+	// ItemTimeout bounds each run. Zero relies on the client timeout.
+	ItemTimeout time.Duration
+	// Scorer scores cases that do not name one; defaults to ExactMatch.
+	Scorer Scorer
+	// ScorerName labels Scorer in results; defaults to "custom".
+	ScorerName string
+	// Scorers adds or overrides named scorers referenced by Case.Scorer.
+	Scorers map[string]Scorer
+	// Label identifies the run in reports, e.g. a deployment or commit.
+	Label string
+	// OnResult is called after each case is scored. Calls are serialized.
+	OnResult func(Result)
+}
+
+// Result is the scored outcome of one case.
+type Result struct {
+	ID         string      `json:"id"`
+	Tags       []string    `json:"tags,omitempty"`
+	Expected   interface{} `json:"expected"`
+	Output     interface{} `json:"output,omitempty"`
+	Scorer     string      `json:"scorer"`
+	Score      float64     `json:"score"`
+	Pass       bool        `json:"pass"`
+	Reason     string      `json:"reason,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	ErrorType  string      `json:"error_type,omitempty"`
+	ErrorCode  string      `json:"error_code,omitempty"`
+	DurationMS int64       `json:"duration_ms"`
+}
+
+// Run executes every case through the client's entrypoint with bounded
+// concurrency and scores the outputs. Runs bypass the response cache so the
+// report reflects the current deployment. Failed runs score 0. The error is
+// non-nil only for invalid options or when ctx is cancelled, in which case
+// the partial report is still returned.
+func Run(ctx context.Context, client *runagent.RunAgentClient, cases []Case, opts Options) (*Report, error) {
+	scorers := builtinScorers()
+	for name, scorer := range opts.Scorers {
+		scorers[name] = scorer
+	}
+	defaultScorer, defaultName := opts.Scorer, opts.ScorerName
+	if defaultName == "" {
+		defaultName = "custom"
+	}
+	if defaultScorer == nil {
+		defaultScorer, defaultName = scorers[ScorerExact], ScorerExact
+	}
+
+	inputs := make([]any, len(cases))
+	for i, c := range cases {
+		if c.Scorer != "" && scorers[c.Scorer] == nil {
+			return nil, &runagent.RunAgentError{
+				Type:    runagent.ErrorTypeValidation,
+				Code:    "UNKNOWN_SCORER",
+				Message: fmt.Sprintf("case %s: unknown scorer %q", c.ID, c.Scorer),
+			}
+		}
+		input, err := c.RunInput()
+		if err != nil {
+			return nil, &runagent.RunAgentError{Type: runagent.ErrorTypeValidation, Message: err.Error()}
+		}
+		input.BypassCache = true
+		inputs[i] = input
+	}
+
+	report := &Report{
+		AgentID:       client.AgentID(),
+		EntrypointTag: client.EntrypointTag(),
+		Label:         opts.Label,
+		StartedAt:     time.Now().UTC(),
+	}
+
+	score := func(res runagent.BatchResult) Result {
+		c := cases[res.Index]
+		result := Result{
+			ID:         c.ID,
+			Tags:       c.Tags,
+			Expected:   c.Expected,
+			Output:     res.Output,
+			Scorer:     defaultName,
+			DurationMS: res.Duration.Milliseconds(),
+		}
+		scorer := defaultScorer
+		if c.Scorer != "" {
+			scorer, result.Scorer = scorers[c.Scorer], c.Scorer
+		}
+
+		if res.Err != nil {
+			result.Error = res.Err.Error()
+			if runErr, ok := runagent.AsRunAgentError(res.Err); ok {
+				result.ErrorType = string(runErr.Type)
+				result.ErrorCode = runErr.Code
+			}
+			return result
+		}
+		s := scorer.Score(c.Expected, res.Output)
+		result.Score, result.Pass, result.Reason = s.Value, s.Pass, s.Reason
+		return result
+	}
+
+	results := make([]Result, len(cases))
+	scored := make([]bool, len(cases))
+	// Score as items complete so OnResult reports progress live.
+	_, err := client.RunBatch(ctx, inputs, runagent.BatchOptions{
+		Concurrency: opts.Concurrency,
+		ItemTimeout: opts.ItemTimeout,
+		OnProgress: func(p runagent.BatchProgress) {
+			result := score(*p.Last)
+			results[p.Last.Index] = result
+			scored[p.Last.Index] = true
+			if opts.OnResult != nil {
+				opts.OnResult(result)
+			}
+		},
+	})
+
+	for i, ok := range scored {
+		if ok {
+			report.Results = append(report.Results, results[i])
+		}
+	}
+	report.Duration = time.Since(report.StartedAt).Seconds()
+	report.Summary = summarize(report.Results)
+	return report, err
+}
@@ -0,0 +1,165 @@
+package eval
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"time"
+)
+
+// scoreEpsilon absorbs float noise when comparing scores.
+const scoreEpsilon = 1e-9
+
+// Summary aggregates a report.
+type Summary struct {
+	Total     int     `json:"total"`
+	Passed    int     `json:"passed"`
+	Failed    int     `json:"failed"`
+	Errored   int     `json:"errored"`
+	PassRate  float64 `json:"pass_rate"`
+	MeanScore float64 `json:"mean_score"`
+}
+
+// Report is the outcome of Run. Save it to compare later runs against it.
+type Report struct {
+	AgentID       string    `json:"agent_id"`
+	EntrypointTag string    `json:"entrypoint_tag"`
+	Label         string    `json:"label,omitempty"`
+	StartedAt     time.Time `json:"started_at"`
+	Duration      float64   `json:"duration_seconds"`
+	Summary       Summary   `json:"summary"`
+	Results       []Result  `json:"results"`
+}
+
+func summarize(results []Result) Summary {
+	s := Summary{Total: len(results)}
+	var total float64
+	for _, r := range results {
+		switch {
+		case r.Error != "":
+			s.Errored++
+		case r.Pass:
+			s.Passed++
+		default:
+			s.Failed++
+		}
+		total += r.Score
+	}
+	if s.Total > 0 {
+		s.PassRate = float64(s.Passed) / float64(s.Total)
+		s.MeanScore = total / float64(s.Total)
+	}
+	return s
+}
+
+// Save writes the report as indented JSON.
+func (r *Report) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// LoadReport reads a report written by Save.
+func LoadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// DiffStatus classifies how a case changed between two reports.
+type DiffStatus string
+
+const (
+	DiffRegressed DiffStatus = "regressed"
+	DiffImproved  DiffStatus = "improved"
+	DiffUnchanged DiffStatus = "unchanged"
+	DiffAdded     DiffStatus = "added"
+	DiffRemoved   DiffStatus = "removed"
+)
+
+// CaseDiff compares one case across reports. Baseline is nil for added
+// cases and Current is nil for removed ones.
+type CaseDiff struct {
+	ID       string     `json:"id"`
+	Status   DiffStatus `json:"status"`
+	Baseline *Result    `json:"baseline,omitempty"`
+	Current  *Result    `json:"current,omitempty"`
+}
+
+// Comparison is the result of Compare.
+type Comparison struct {
+	Baseline       Summary    `json:"baseline"`
+	Current        Summary    `json:"current"`
+	PassRateDelta  float64    `json:"pass_rate_delta"`
+	MeanScoreDelta float64    `json:"mean_score_delta"`
+	Regressions    []CaseDiff `json:"regressions"`
+	Improvements   []CaseDiff `json:"improvements"`
+	Added          []CaseDiff `json:"added"`
+	Removed        []CaseDiff `json:"removed"`
+	Unchanged      int        `json:"unchanged"`
+}
+
+// HasRegressions reports whether any case that exists in both reports
+// passed in the baseline and no longer does, or lost score.
+func (c *Comparison) HasRegressions() bool { return len(c.Regressions) > 0 }
+
+// Compare matches cases by ID. A case regresses when it stops passing or
+// its score drops, and improves in the opposite case.
+func Compare(baseline, current *Report) *Comparison {
+	cmp := &Comparison{
+		Baseline:       baseline.Summary,
+		Current:        current.Summary,
+		PassRateDelta:  current.Summary.PassRate - baseline.Summary.PassRate,
+		MeanScoreDelta: current.Summary.MeanScore - baseline.Summary.MeanScore,
+		Regressions:    []CaseDiff{},
+		Improvements:   []CaseDiff{},
+		Added:          []CaseDiff{},
+		Removed:        []CaseDiff{},
+	}
+
+	before := make(map[string]*Result, len(baseline.Results))
+	for i := range baseline.Results {
+		before[baseline.Results[i].ID] = &baseline.Results[i]
+	}
+	seen := make(map[string]bool, len(current.Results))
+
+	for i := range current.Results {
+		cur := &current.Results[i]
+		seen[cur.ID] = true
+		base, ok := before[cur.ID]
+		if !ok {
+			cmp.Added = append(cmp.Added, CaseDiff{ID: cur.ID, Status: DiffAdded, Current: cur})
+			continue
+		}
+		diff := CaseDiff{ID: cur.ID, Baseline: base, Current: cur}
+		switch {
+		case base.Pass && !cur.Pass, cur.Score < base.Score-scoreEpsilon:
+			diff.Status = DiffRegressed
+			cmp.Regressions = append(cmp.Regressions, diff)
+		case !base.Pass && cur.Pass, cur.Score > base.Score+scoreEpsilon:
+			diff.Status = DiffImproved
+			cmp.Improvements = append(cmp.Improvements, diff)
+		default:
+			cmp.Unchanged++
+		}
+	}
+	for i := range baseline.Results {
+		base := &baseline.Results[i]
+		if !seen[base.ID] {
+			cmp.Removed = append(cmp.Removed, CaseDiff{ID: base.ID, Status: DiffRemoved, Baseline: base})
+		}
+	}
+
+	for _, list := range [][]CaseDiff{cmp.Regressions, cmp.Improvements, cmp.Added, cmp.Removed} {
+		sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	}
+	return cmp
+}
@@ -0,0 +1,272 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Score is a scorer's verdict for one case.
+type Score struct {
+	// Value is between 0 and 1; partial credit is allowed.
+	Value float64 `json:"value"`
+	// Pass reports whether the case counts as passed.
+	Pass bool `json:"pass"`
+	// Reason explains a failure or partial score.
+	Reason string `json:"reason,omitempty"`
+}
+
+// Scorer compares an entrypoint's output against the expected value.
+type Scorer interface {
+	Score(expected, actual interface{}) Score
+}
+
+// ScorerFunc adapts a function to Scorer.
+type ScorerFunc func(expected, actual interface{}) Score
+
+// Score calls f.
+func (f ScorerFunc) Score(expected, actual interface{}) Score { return f(expected, actual) }
+
+// DefaultNumericTolerance is the tolerance of the built-in "numeric" scorer.
+const DefaultNumericTolerance = 1e-6
+
+// Built-in scorer names usable in a dataset's "scorer" field.
+const (
+	ScorerExact      = "exact"
+	ScorerJSONSubset = "json_subset"
+	ScorerRegex      = "regex"
+	ScorerNumeric    = "numeric"
+)
+
+func builtinScorers() map[string]Scorer {
+	return map[string]Scorer{
+		ScorerExact:      ExactMatch(),
+		ScorerJSONSubset: JSONSubset(),
+		ScorerRegex:      Regex(),
+		ScorerNumeric:    NumericTolerance(DefaultNumericTolerance),
+	}
+}
+
+// Builtin returns the built-in scorer with the given name.
+func Builtin(name string) (Scorer, bool) {
+	scorer, ok := builtinScorers()[name]
+	return scorer, ok
+}
+
+func pass() Score { return Score{Value: 1, Pass: true} }
+
+func fail(format string, args ...interface{}) Score {
+	return Score{Reason: fmt.Sprintf(format, args...)}
+}
+
+// ExactMatch passes when the output equals the expected value after both are
+// normalized through JSON, so 5 and 5.0 compare equal.
+func ExactMatch() Scorer {
+	return ScorerFunc(func(expected, actual interface{}) Score {
+		if reflect.DeepEqual(normalize(expected), normalize(actual)) {
+			return pass()
+		}
+		return fail("expected %s, got %s", render(expected), render(actual))
+	})
+}
+
+// JSONSubset passes when every field in the expected value is present in the
+// output with a matching value; extra output fields are ignored. Arrays
+// match element by element. The score is the fraction of expected leaf
+// values that matched. A string output holding JSON is decoded first.
+func JSONSubset() Scorer {
+	return ScorerFunc(func(expected, actual interface{}) Score {
+		var mismatches []string
+		matched, total := subsetMatch("$", normalize(expected), decodeJSONString(normalize(actual)), &mismatches)
+		if total == 0 {
+			return pass()
+		}
+		score := Score{Value: float64(matched) / float64(total), Pass: matched == total}
+		if len(mismatches) > 0 {
+			score.Reason = strings.Join(mismatches, "; ")
+		}
+		return score
+	})
+}
+
+func subsetMatch(path string, expected, actual interface{}, mismatches *[]string) (matched, total int) {
+	switch exp := expected.(type) {
+	case map[string]interface{}:
+		act, ok := actual.(map[string]interface{})
+		if !ok {
+			*mismatches = append(*mismatches, fmt.Sprintf("%s: expected object, got %s", path, render(actual)))
+			return 0, countLeaves(exp)
+		}
+		keys := make([]string, 0, len(exp))
+		for k := range exp {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			value, ok := act[k]
+			if !ok {
+				*mismatches = append(*mismatches, fmt.Sprintf("%s.%s missing", path, k))
+				total += countLeaves(exp[k])
+				continue
+			}
+			m, t := subsetMatch(path+"."+k, exp[k], value, mismatches)
+			matched += m
+			total += t
+		}
+		return matched, total
+	case []interface{}:
+		act, ok := actual.([]interface{})
+		if !ok || len(act) != len(exp) {
+			*mismatches = append(*mismatches, fmt.Sprintf("%s: expected %d-element array, got %s", path, len(exp), render(actual)))
+			return 0, countLeaves(exp)
+		}
+		for i := range exp {
+			m, t := subsetMatch(fmt.Sprintf("%s[%d]", path, i), exp[i], act[i], mismatches)
+			matched += m
+			total += t
+		}
+		return matched, total
+	default:
+		if reflect.DeepEqual(expected, actual) {
+			return 1, 1
+		}
+		*mismatches = append(*mismatches, fmt.Sprintf("%s: expected %s, got %s", path, render(expected), render(actual)))
+		return 0, 1
+	}
+}
+
+func countLeaves(v interface{}) int {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		n := 0
+		for _, child := range t {
+			n += countLeaves(child)
+		}
+		return n
+	case []interface{}:
+		n := 0
+		for _, child := range t {
+			n += countLeaves(child)
+		}
+		return n
+	default:
+		return 1
+	}
+}
+
+// Regex passes when the output matches the expected value, which must be a
+// regular expression string. Non-string outputs are matched against their
+// JSON encoding.
+func Regex() Scorer {
+	var mu sync.Mutex
+	compiled := map[string]*regexp.Regexp{}
+	return ScorerFunc(func(expected, actual interface{}) Score {
+		pattern, ok := expected.(string)
+		if !ok {
+			return fail("regex scorer needs a string pattern, got %s", render(expected))
+		}
+		mu.Lock()
+		re, ok := compiled[pattern]
+		if !ok {
+			var err error
+			if re, err = regexp.Compile(pattern); err != nil {
+				mu.Unlock()
+				return fail("invalid pattern %q: %v", pattern, err)
+			}
+			compiled[pattern] = re
+		}
+		mu.Unlock()
+
+		text, ok := actual.(string)
+		if !ok {
+			text = render(actual)
+		}
+		if re.MatchString(text) {
+			return pass()
+		}
+		return fail("%s does not match /%s/", render(actual), pattern)
+	})
+}
+
+// NumericTolerance passes when the output is within tolerance of the
+// expected number. Numeric strings are accepted on either side.
+func NumericTolerance(tolerance float64) Scorer {
+	return ScorerFunc(func(expected, actual interface{}) Score {
+		want, ok := toFloat(expected)
+		if !ok {
+			return fail("expected value %s is not a number", render(expected))
+		}
+		got, ok := toFloat(actual)
+		if !ok {
+			return fail("output %s is not a number", render(actual))
+		}
+		if diff := math.Abs(got - want); diff > tolerance {
+			return fail("expected %v ± %v, got %v", want, tolerance, got)
+		}
+		return pass()
+	})
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch t := decodeJSONString(normalize(v)).(type) {
+	case float64:
+		return t, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(t), 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// normalize round-trips v through JSON so Go values compare like decoded ones.
+func normalize(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return v
+	}
+	return out
+}
+
+// decodeJSONString decodes strings holding a JSON object, array or number.
+func decodeJSONString(v interface{}) interface{} {
+	s, ok := v.(string)
+	if !ok {
+		return v
+	}
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return v
+	}
+	var out interface{}
+	if err := json.Unmarshal([]byte(trimmed), &out); err != nil {
+		return v
+	}
+	switch out.(type) {
+	case map[string]interface{}, []interface{}, float64:
+		return out
+	default:
+		return v
+	}
+}
+
+func render(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	if len(data) > 200 {
+		return string(data[:197]) + "..."
+	}
+	return string(data)
+}
@@ -0,0 +1,116 @@
+package eval
+
+import "testing"
+
+func TestJSONSubset(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected interface{}
+		actual   interface{}
+		value    float64
+		pass     bool
+	}{
+		{
+			name:     "extra fields ignored",
+			expected: map[string]interface{}{"a": 1},
+			actual:   map[string]interface{}{"a": 1.0, "b": "x"},
+			value:    1, pass: true,
+		},
+		{
+			name:     "nested partial credit",
+			expected: map[string]interface{}{"a": 1, "b": map[string]interface{}{"c": "x", "d": true}},
+			actual:   map[string]interface{}{"a": 1, "b": map[string]interface{}{"c": "y", "d": true}},
+			value:    2.0 / 3, pass: false,
+		},
+		{
+			name:     "missing key",
+			expected: map[string]interface{}{"a": 1, "b": 2},
+			actual:   map[string]interface{}{"a": 1},
+			value:    0.5, pass: false,
+		},
+		{
+			name:     "arrays match element by element",
+			expected: []interface{}{1, map[string]interface{}{"k": "v"}},
+			actual:   []interface{}{1, map[string]interface{}{"k": "v", "extra": 0}},
+			value:    1, pass: true,
+		},
+		{
+			name:     "array length mismatch",
+			expected: []interface{}{1, 2},
+			actual:   []interface{}{1},
+			value:    0, pass: false,
+		},
+		{
+			name:     "JSON string output is decoded",
+			expected: map[string]interface{}{"a": 1},
+			actual:   `{"a": 1, "b": 2}`,
+			value:    1, pass: true,
+		},
+		{
+			name:     "empty expectation passes",
+			expected: map[string]interface{}{},
+			actual:   "anything",
+			value:    1, pass: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := JSONSubset().Score(tt.expected, tt.actual)
+			if got.Value != tt.value || got.Pass != tt.pass {
+				t.Errorf("Score = %+v, want value %v pass %v", got, tt.value, tt.pass)
+			}
+			if !got.Pass && got.Reason == "" {
+				t.Error("failed score has no reason")
+			}
+		})
+	}
+}
+
+func TestRegex(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected interface{}
+		actual   interface{}
+		pass     bool
+	}{
+		{name: "match", expected: `^hello\b`, actual: "hello world", pass: true},
+		{name: "no match", expected: `^bye`, actual: "hello world", pass: false},
+		{name: "non-string output uses JSON", expected: `"id":\s*7`, actual: map[string]interface{}{"id": 7}, pass: true},
+		{name: "invalid pattern", expected: `(`, actual: "(", pass: false},
+		{name: "non-string pattern", expected: 5, actual: "5", pass: false},
+	}
+	scorer := Regex()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := scorer.Score(tt.expected, tt.actual)
+			if got.Pass != tt.pass {
+				t.Errorf("Score = %+v, want pass %v", got, tt.pass)
+			}
+		})
+	}
+}
+
+func TestNumericTolerance(t *testing.T) {
+	tests := []struct {
+		name      string
+		tolerance float64
+		expected  interface{}
+		actual    interface{}
+		pass      bool
+	}{
+		{name: "equal", tolerance: 0, expected: 5, actual: 5.0, pass: true},
+		{name: "within tolerance", tolerance: 0.01, expected: 1.0, actual: 1.005, pass: true},
+		{name: "outside tolerance", tolerance: 0.01, expected: 1.0, actual: 1.02, pass: false},
+		{name: "numeric strings", tolerance: 0.1, expected: "3.14", actual: " 3.1 ", pass: true},
+		{name: "non-numeric output", tolerance: 1, expected: 1, actual: "one", pass: false},
+		{name: "non-numeric expected", tolerance: 1, expected: true, actual: 1, pass: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NumericTolerance(tt.tolerance).Score(tt.expected, tt.actual)
+			if got.Pass != tt.pass {
+				t.Errorf("Score = %+v, want pass %v", got, tt.pass)
+			}
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package runagent
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/runagent-dev/runagent-go/internal/constants"
+)
+
+// Framework names the agent framework behind a deployment.
+type Framework = constants.Framework
+
+// Frameworks with dedicated adapters. Any other value uses the default one.
+const (
+	FrameworkLangGraph  = constants.FrameworkLangGraph
+	FrameworkLangChain  = constants.FrameworkLangChain
+	FrameworkLlamaIndex = constants.FrameworkLlamaIndex
+	FrameworkCrewAI     = constants.FrameworkCrewAI
+	FrameworkAutoGen    = constants.FrameworkAutoGen
+	FrameworkDefault    = constants.FrameworkDefault
+)
+
+type stateToken struct{ m map[string]any }
+
+// State passes the framework's native input object: a LangGraph state dict,
+// a LangChain runnable input or CrewAI kickoff inputs. Other frameworks
+// receive its entries as kwargs. Messages passed alongside are merged in.
+func State(m map[string]any) stateToken { return stateToken{m: m} }
+
+// FrameworkAdapter shapes Message and State inputs into the arguments a
+// framework's entrypoints expect, and normalizes outputs into messages.
+type FrameworkAdapter interface {
+	Framework() Framework
+	// AdaptInput moves RunInput.Messages and RunInput.State into args and
+	// kwargs. Plain args and kwargs pass through unchanged.
+	AdaptInput(input RunInput) (RunInput, error)
+	// Messages normalizes an output into chat messages. Unrecognized
+	// outputs become a single assistant message.
+	Messages(output interface{}) []Message
+}
+
+// AdapterFor returns the adapter for a framework. Unknown or empty
+// frameworks get the default adapter.
+func AdapterFor(framework Framework) FrameworkAdapter {
+	switch Framework(strings.ToLower(strings.TrimSpace(string(framework)))) {
+	case FrameworkLangGraph:
+		return &adapter{framework: FrameworkLangGraph, input: langGraphInput, output: langGraphOutput}
+	case FrameworkLangChain:
+		return &adapter{framework: FrameworkLangChain, input: langChainInput, output: langChainOutput}
+	case FrameworkLlamaIndex:
+		return &adapter{framework: FrameworkLlamaIndex, input: llamaIndexInput, output: llamaIndexOutput}
+	case FrameworkCrewAI:
+		return &adapter{framework: FrameworkCrewAI, input: crewAIInput, output: crewAIOutput}
+	case FrameworkAutoGen:
+		return &adapter{framework: FrameworkAutoGen, input: kwargsInput, output: autoGenOutput}
+	default:
+		return &adapter{framework: FrameworkDefault, input: kwargsInput, output: parseKnownMessages}
+	}
+}
+
+type adapter struct {
+	framework Framework
+	input     func(in *RunInput, messages []Message, state map[string]interface{}) error
+	output    func(output interface{}) []Message
+}
+
+func (a *adapter) Framework() Framework { return a.framework }
+
+func (a *adapter) AdaptInput(input RunInput) (RunInput, error) {
+	if len(input.Messages) == 0 && input.State == nil {
+		return input, nil
+	}
+	messages, state := input.Messages, input.State
+	input.Messages, input.State = nil, nil
+	input.InputArgs = append([]interface{}(nil), input.InputArgs...)
+	kwargs := make(map[string]interface{}, len(input.InputKwargs))
+	for k, v := range input.InputKwargs {
+		kwargs[k] = v
+	}
+	input.InputKwargs = kwargs
+	if err := a.input(&input, messages, state); err != nil {
+		return RunInput{}, err
+	}
+	return input, nil
+}
+
+func (a *adapter) Messages(output interface{}) []Message {
+	if output == nil {
+		return nil
+	}
+	if msgs := a.output(output); len(msgs) > 0 {
+		return msgs
+	}
+	return []Message{rawMessage(output)}
+}
+
+// langGraphInput passes the state dict as the graph input, with messages
+// under "messages" as StateGraph's add_messages reducer expects.
+func langGraphInput(in *RunInput, messages []Message, state map[string]interface{}) error {
+	obj := copyMap(state)
+	if len(messages) > 0 {
+		obj["messages"] = messageMaps(messages)
+	}
+	in.InputArgs = append([]interface{}{obj}, in.InputArgs...)
+	return nil
+}
+
+// langChainInput passes a runnable input dict: the latest user message as
+// "input" and earlier turns as "chat_history".
+func langChainInput(in *RunInput, messages []Message, state map[string]interface{}) error {
+	obj := copyMap(state)
+	if len(messages) > 0 {
+		prompt, history := splitPrompt(messages)
+		obj["input"] = prompt
+		if len(history) > 0 {
+			obj["chat_history"] = messageMaps(history)
+		}
+	}
+	in.InputArgs = append([]interface{}{obj}, in.InputArgs...)
+	return nil
+}
+
+// llamaIndexInput mirrors chat_engine.chat(message, chat_history).
+func llamaIndexInput(in *RunInput, messages []Message, state map[string]interface{}) error {
+	if err := mergeKwargs(in, state); err != nil {
+		return err
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	prompt, history := splitPrompt(messages)
+	if err := setKwarg(in, "message", prompt); err != nil {
+		return err
+	}
+	if len(history) > 0 {
+		return setKwarg(in, "chat_history", messageMaps(history))
+	}
+	return nil
+}
+
+// crewAIInput mirrors crew.kickoff(inputs=...). CrewAI interpolates inputs
+// into task text, so earlier turns are rendered as a plain transcript.
+func crewAIInput(in *RunInput, messages []Message, state map[string]interface{}) error {
+	inputs := copyMap(state)
+	if len(messages) > 0 {
+		prompt, history := splitPrompt(messages)
+		inputs["input"] = prompt
+		if len(history) > 0 {
+			lines := make([]string, len(history))
+			for i, m := range history {
+				lines[i] = fmt.Sprintf("%s: %s", firstNonEmpty(m.Name, string(m.Role)), m.Content)
+			}
+			inputs["history"] = strings.Join(lines, "\n")
+		}
+	}
+	return setKwarg(in, "inputs", inputs)
+}
+
+// kwargsInput passes state entries as kwargs and messages as an
+// OpenAI-style "messages" list, which AutoGen agents accept directly.
+func kwargsInput(in *RunInput, messages []Message, state map[string]interface{}) error {
+	if err := mergeKwargs(in, state); err != nil {
+		return err
+	}
+	if len(messages) > 0 {
+		return setKwarg(in, "messages", messageMaps(messages))
+	}
+	return nil
+}
+
+// splitPrompt returns the last user message's content and the messages
+// before it. Without a user message the last message is the prompt.
+func splitPrompt(messages []Message) (string, []Message) {
+	last := len(messages) - 1
+	for i := last; i >= 0; i-- {
+		if messages[i].Role == RoleUser {
+			last = i
+			break
+		}
+	}
+	return messages[last].Content, messages[:last]
+}
+
+func setKwarg(in *RunInput, key string, value interface{}) error {
+	if _, exists := in.InputKwargs[key]; exists {
+		return newError(
+			ErrorTypeValidation,
+			fmt.Sprintf("kwarg %q is also set from Message or State input", key),
+			withCode("INPUT_CONFLICT"),
+			withSuggestion("Pass the value either as a kwarg or through Message/State, not both"),
+		)
+	}
+	in.InputKwargs[key] = value
+	return nil
+}
+
+func mergeKwargs(in *RunInput, state map[string]interface{}) error {
+	for k, v := range state {
+		if err := setKwarg(in, k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(m)+2)
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+func langGraphOutput(output interface{}) []Message {
+	if m, ok := output.(map[string]interface{}); ok {
+		if msgs := parseMessageList(m["messages"]); len(msgs) > 0 {
+			return msgs
+		}
+	}
+	return parseKnownMessages(output)
+}
+
+func langChainOutput(output interface{}) []Message {
+	if m, ok := output.(map[string]interface{}); ok {
+		// AgentExecutor returns {"input": ..., "output": ...}.
+		if text, ok := m["output"].(string); ok {
+			return []Message{{Role: RoleAssistant, Content: text, Raw: output}}
+		}
+	}
+	return parseKnownMessages(output)
+}
+
+func llamaIndexOutput(output interface{}) []Message {
+	if m, ok := output.(map[string]interface{}); ok {
+		// Query and chat engines return {"response": ..., "source_nodes": ...}.
+		if text, ok := m["response"].(string); ok {
+			return []Message{{Role: RoleAssistant, Content: text, Raw: output}}
+		}
+		if msgs, ok := parseMessageValue(m["message"]); ok {
+			return msgs
+		}
+	}
+	return parseKnownMessages(output)
+}
+
+func crewAIOutput(output interface{}) []Message {
+	if m, ok := output.(map[string]interface{}); ok {
+		// CrewOutput carries the final answer in "raw".
+		if text, ok := m["raw"].(string); ok {
+			return []Message{{Role: RoleAssistant, Content: text, Raw: output}}
+		}
+	}
+	return parseKnownMessages(output)
+}
+
+// autoGenOutput reads ChatResult {"chat_history": [...], "summary": ...}
+// and TaskResult {"messages": [...]}.
+func autoGenOutput(output interface{}) []Message {
+	if m, ok := output.(map[string]interface{}); ok {
+		if msgs := parseMessageList(m["chat_history"]); len(msgs) > 0 {
+			return msgs
+		}
+		if msgs := parseMessageList(m["messages"]); len(msgs) > 0 {
+			return msgs
+		}
+		if text, ok := m["summary"].(string); ok {
+			return []Message{{Role: RoleAssistant, Content: text, Raw: output}}
+		}
+	}
+	return parseKnownMessages(output)
+}
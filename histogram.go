@@ -0,0 +1,223 @@
+package runagent
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"time"
+)
+
+// Histogram precision: three significant decimal digits, as in HdrHistogram.
+const (
+	histogramSubBucketHalfCountMagnitude = 10
+	histogramSubBucketHalfCount          = 1 << histogramSubBucketHalfCountMagnitude
+	histogramSubBucketMask               = 2*histogramSubBucketHalfCount - 1
+)
+
+// Histogram is a High Dynamic Range histogram of latencies recorded in
+// microseconds with three significant digits of precision. It is not safe
+// for concurrent use.
+type Histogram struct {
+	counts []int64
+	total  int64
+	min    int64
+	max    int64
+	sum    float64
+	sumSq  float64
+}
+
+// NewHistogram returns an empty histogram.
+func NewHistogram() *Histogram {
+	return &Histogram{min: math.MaxInt64}
+}
+
+// Record adds a duration, rounded to the microsecond.
+func (h *Histogram) Record(d time.Duration) {
+	h.RecordValue(d.Microseconds())
+}
+
+// RecordValue adds a raw value in microseconds. Negative values count as 0.
+func (h *Histogram) RecordValue(v int64) {
+	if v < 0 {
+		v = 0
+	}
+	idx := histogramCountsIndex(v)
+	if idx >= len(h.counts) {
+		grown := make([]int64, idx+histogramSubBucketHalfCount)
+		copy(grown, h.counts)
+		h.counts = grown
+	}
+	h.counts[idx]++
+	h.total++
+	if v < h.min {
+		h.min = v
+	}
+	if v > h.max {
+		h.max = v
+	}
+	f := float64(v)
+	h.sum += f
+	h.sumSq += f * f
+}
+
+// TotalCount returns the number of recorded values.
+func (h *Histogram) TotalCount() int64 { return h.total }
+
+// Min returns the smallest recorded value.
+func (h *Histogram) Min() time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	return time.Duration(h.min) * time.Microsecond
+}
+
+// Max returns the largest recorded value.
+func (h *Histogram) Max() time.Duration {
+	return time.Duration(h.max) * time.Microsecond
+}
+
+// Mean returns the arithmetic mean of recorded values.
+func (h *Histogram) Mean() time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	return time.Duration(h.sum / float64(h.total) * float64(time.Microsecond))
+}
+
+// StdDev returns the standard deviation of recorded values.
+func (h *Histogram) StdDev() time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	mean := h.sum / float64(h.total)
+	variance := h.sumSq/float64(h.total) - mean*mean
+	if variance < 0 {
+		variance = 0
+	}
+	return time.Duration(math.Sqrt(variance) * float64(time.Microsecond))
+}
+
+// ValueAtPercentile returns the value below which the given percentage
+// (0-100) of recorded values fall, within the histogram's precision.
+func (h *Histogram) ValueAtPercentile(percentile float64) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	percentile = math.Min(math.Max(percentile, 0), 100)
+	target := int64(percentile/100*float64(h.total) + 0.5)
+	if target < 1 {
+		target = 1
+	}
+	var cumulative int64
+	for idx, count := range h.counts {
+		cumulative += count
+		if cumulative >= target {
+			return time.Duration(h.highestEquivalentValue(idx)) * time.Microsecond
+		}
+	}
+	return h.Max()
+}
+
+// Merge adds all values recorded in other to h.
+func (h *Histogram) Merge(other *Histogram) {
+	if other == nil || other.total == 0 {
+		return
+	}
+	if len(other.counts) > len(h.counts) {
+		grown := make([]int64, len(other.counts))
+		copy(grown, h.counts)
+		h.counts = grown
+	}
+	for idx, count := range other.counts {
+		h.counts[idx] += count
+	}
+	h.total += other.total
+	h.sum += other.sum
+	h.sumSq += other.sumSq
+	if other.min < h.min {
+		h.min = other.min
+	}
+	if other.max > h.max {
+		h.max = other.max
+	}
+}
+
+// WritePercentileDistribution writes the histogram in the HdrHistogram
+// percentile distribution (.hgrm) format, with values in milliseconds, so
+// it can be loaded by the standard HdrHistogram plotting tools.
+func (h *Histogram) WritePercentileDistribution(w io.Writer) error {
+	const ticksPerHalfDistance = 5
+	const scale = 1000.0 // microseconds per millisecond
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%12s %14s %10s %14s\n\n", "Value", "Percentile", "TotalCount", "1/(1-Percentile)")
+
+	if h.total > 0 {
+		var cumulative int64
+		percentile := 0.0
+		for idx, count := range h.counts {
+			if count == 0 {
+				continue
+			}
+			cumulative += count
+			value := float64(h.highestEquivalentValue(idx)) / scale
+			for float64(cumulative)*100/float64(h.total) >= percentile {
+				fmt.Fprintf(bw, "%12.3f %2.12f %10d %14.2f\n", value, percentile/100, cumulative, 1/(1-percentile/100))
+				if cumulative == h.total {
+					break
+				}
+				ticks := ticksPerHalfDistance * math.Pow(2, math.Floor(math.Log2(100/(100-percentile)))+1)
+				percentile += 100 / ticks
+			}
+			if cumulative == h.total {
+				break
+			}
+		}
+		fmt.Fprintf(bw, "%12.3f %2.12f %10d\n", float64(h.max)/scale, 1.0, h.total)
+	}
+
+	fmt.Fprintf(bw, "#[Mean    = %12.3f, StdDeviation   = %12.3f]\n",
+		float64(h.Mean())/float64(time.Millisecond), float64(h.StdDev())/float64(time.Millisecond))
+	fmt.Fprintf(bw, "#[Max     = %12.3f, Total count    = %12d]\n", float64(h.max)/scale, h.total)
+	fmt.Fprintf(bw, "#[Buckets = %12d, SubBuckets     = %12d]\n",
+		len(h.counts)/histogramSubBucketHalfCount, 2*histogramSubBucketHalfCount)
+	return bw.Flush()
+}
+
+// highestEquivalentValue is the upper end of the bucket at idx, capped at
+// the largest recorded value.
+func (h *Histogram) highestEquivalentValue(idx int) int64 {
+	v := histogramHighestEquivalentValue(histogramValueFromIndex(idx))
+	if v > h.max {
+		return h.max
+	}
+	return v
+}
+
+func histogramBucketIndex(v int64) int {
+	return 64 - bits.LeadingZeros64(uint64(v)|histogramSubBucketMask) - (histogramSubBucketHalfCountMagnitude + 1)
+}
+
+func histogramCountsIndex(v int64) int {
+	bucket := histogramBucketIndex(v)
+	subBucket := int(v >> uint(bucket))
+	return (bucket+1)<<histogramSubBucketHalfCountMagnitude + subBucket - histogramSubBucketHalfCount
+}
+
+func histogramValueFromIndex(idx int) int64 {
+	bucket := (idx >> histogramSubBucketHalfCountMagnitude) - 1
+	subBucket := (idx & (histogramSubBucketHalfCount - 1)) + histogramSubBucketHalfCount
+	if bucket < 0 {
+		subBucket -= histogramSubBucketHalfCount
+		bucket = 0
+	}
+	return int64(subBucket) << uint(bucket)
+}
+
+// histogramHighestEquivalentValue is the largest value that shares v's bucket.
+func histogramHighestEquivalentValue(v int64) int64 {
+	bucket := histogramBucketIndex(v)
+	return v + int64(1)<<uint(bucket) - 1
+}
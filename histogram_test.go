@@ -0,0 +1,113 @@
+package runagent
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistogramBucketMath(t *testing.T) {
+	tests := []struct {
+		value     int64
+		index     int
+		lowest    int64
+		highest   int64
+		bucketIdx int
+	}{
+		{value: 0, index: 0, lowest: 0, highest: 0, bucketIdx: 0},
+		{value: 1, index: 1, lowest: 1, highest: 1, bucketIdx: 0},
+		{value: 2047, index: 2047, lowest: 2047, highest: 2047, bucketIdx: 0},
+		{value: 2048, index: 2048, lowest: 2048, highest: 2049, bucketIdx: 1},
+		{value: 2049, index: 2048, lowest: 2048, highest: 2049, bucketIdx: 1},
+		{value: 4095, index: 3071, lowest: 4094, highest: 4095, bucketIdx: 1},
+		{value: 4096, index: 3072, lowest: 4096, highest: 4099, bucketIdx: 2},
+		{value: 1_000_000, index: 11169, lowest: 999_936, highest: 1_000_447, bucketIdx: 9},
+	}
+	for _, tt := range tests {
+		if got := histogramBucketIndex(tt.value); got != tt.bucketIdx {
+			t.Errorf("histogramBucketIndex(%d) = %d, want %d", tt.value, got, tt.bucketIdx)
+		}
+		idx := histogramCountsIndex(tt.value)
+		if idx != tt.index {
+			t.Errorf("histogramCountsIndex(%d) = %d, want %d", tt.value, idx, tt.index)
+		}
+		lowest := histogramValueFromIndex(idx)
+		if lowest != tt.lowest {
+			t.Errorf("histogramValueFromIndex(%d) = %d, want %d", idx, lowest, tt.lowest)
+		}
+		if got := histogramHighestEquivalentValue(lowest); got != tt.highest {
+			t.Errorf("histogramHighestEquivalentValue(%d) = %d, want %d", lowest, got, tt.highest)
+		}
+	}
+}
+
+func TestHistogramPrecision(t *testing.T) {
+	// Every bucket must hold its value within three significant digits.
+	for _, v := range []int64{3000, 12_345, 999_999, 7_654_321, 123_456_789} {
+		lowest := histogramValueFromIndex(histogramCountsIndex(v))
+		highest := histogramHighestEquivalentValue(lowest)
+		if v < lowest || v > highest {
+			t.Errorf("value %d outside its bucket [%d, %d]", v, lowest, highest)
+		}
+		if rel := float64(highest-lowest) / float64(v); rel > 0.001 {
+			t.Errorf("value %d: bucket width %d exceeds 0.1%%", v, highest-lowest)
+		}
+	}
+}
+
+func TestHistogramPercentiles(t *testing.T) {
+	h := NewHistogram()
+	for v := int64(1); v <= 10_000; v++ {
+		h.RecordValue(v)
+	}
+
+	tests := []struct {
+		percentile float64
+		want       time.Duration
+	}{
+		{percentile: 0, want: 1 * time.Microsecond},
+		{percentile: 50, want: 5003 * time.Microsecond},
+		{percentile: 90, want: 9007 * time.Microsecond},
+		{percentile: 99, want: 9903 * time.Microsecond},
+		{percentile: 100, want: 10_000 * time.Microsecond},
+		{percentile: 150, want: 10_000 * time.Microsecond},
+	}
+	for _, tt := range tests {
+		if got := h.ValueAtPercentile(tt.percentile); got != tt.want {
+			t.Errorf("ValueAtPercentile(%v) = %v, want %v", tt.percentile, got, tt.want)
+		}
+	}
+
+	if got := h.TotalCount(); got != 10_000 {
+		t.Errorf("TotalCount() = %d, want 10000", got)
+	}
+	if got := h.Min(); got != time.Microsecond {
+		t.Errorf("Min() = %v, want 1µs", got)
+	}
+	if got := h.Max(); got != 10*time.Millisecond {
+		t.Errorf("Max() = %v, want 10ms", got)
+	}
+	if got := h.Mean(); got != 5000500*time.Nanosecond {
+		t.Errorf("Mean() = %v, want 5.0005ms", got)
+	}
+}
+
+func TestHistogramMerge(t *testing.T) {
+	a, b := NewHistogram(), NewHistogram()
+	a.Record(2 * time.Millisecond)
+	b.Record(time.Microsecond)
+	b.Record(time.Second)
+	a.Merge(b)
+
+	if got := a.TotalCount(); got != 3 {
+		t.Errorf("TotalCount() = %d, want 3", got)
+	}
+	if got := a.Min(); got != time.Microsecond {
+		t.Errorf("Min() = %v, want 1µs", got)
+	}
+	if got := a.Max(); got != time.Second {
+		t.Errorf("Max() = %v, want 1s", got)
+	}
+	if got := a.ValueAtPercentile(50); got != 2*time.Millisecond {
+		t.Errorf("ValueAtPercentile(50) = %v, want 2ms", got)
+	}
+}
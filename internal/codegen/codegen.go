@@ -0,0 +1,354 @@
+// Package codegen renders typed Go clients from an agent architecture.
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/runagent-dev/runagent-go"
+)
+
+// Options control Generate.
+type Options struct {
+	// Package is the generated package name.
+	Package string
+	// AgentID is embedded as the default agent; defaults to the
+	// architecture's agent ID.
+	AgentID string
+	// Source describes where the architecture came from, for the header.
+	Source string
+	// Command is the command line recorded in the header.
+	Command string
+}
+
+// Generate returns gofmt-ed source for a package with one method per
+// entrypoint. Entrypoints with a declared schema get an input struct;
+// the rest accept the SDK's variadic values. Stream tags return a
+// *runagent.StreamIterator.
+func Generate(arch *runagent.AgentArchitecture, opts Options) ([]byte, error) {
+	if arch == nil || len(arch.Entrypoints) == 0 {
+		return nil, fmt.Errorf("architecture has no entrypoints")
+	}
+	if opts.Package == "" {
+		return nil, fmt.Errorf("package name is required")
+	}
+	if !isIdentifier(opts.Package) {
+		return nil, fmt.Errorf("invalid package name %q", opts.Package)
+	}
+	agentID := opts.AgentID
+	if agentID == "" {
+		agentID = arch.AgentID
+	}
+
+	g := &generator{
+		names:   map[string]bool{"Client": true, "New": true, "AgentID": true},
+		structs: map[string]bool{},
+	}
+	entrypoints := append([]runagent.EntryPoint(nil), arch.Entrypoints...)
+	sort.SliceStable(entrypoints, func(i, j int) bool { return entrypoints[i].Tag < entrypoints[j].Tag })
+	for _, ep := range entrypoints {
+		if ep.Tag == "" {
+			return nil, fmt.Errorf("entrypoint without tag")
+		}
+		g.entrypoint(ep)
+	}
+
+	var out bytes.Buffer
+	command := opts.Command
+	if command == "" {
+		command = "runagent-go gen"
+	}
+	fmt.Fprintf(&out, "// Code generated by %s; DO NOT EDIT.\n", command)
+	if opts.Source != "" {
+		fmt.Fprintf(&out, "// Source: %s\n", opts.Source)
+	}
+	fmt.Fprintf(&out, "\n// Package %s is a typed client for the %s agent.\n", opts.Package, orDefault(agentID, "RunAgent"))
+	fmt.Fprintf(&out, "package %s\n\n", opts.Package)
+	out.WriteString("import (\n\t\"context\"\n\t\"sync\"\n\n\t\"github.com/runagent-dev/runagent-go\"\n)\n\n")
+	fmt.Fprintf(&out, "// AgentID is the agent this client was generated from.\nconst AgentID = %s\n\n", strconv.Quote(agentID))
+	out.WriteString(clientPreamble)
+	out.Write(g.methods.Bytes())
+	out.Write(g.types.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w", err)
+	}
+	return src, nil
+}
+
+const clientPreamble = `// Client calls the agent's entrypoints with typed inputs. It creates one
+// runagent.RunAgentClient per entrypoint on first use.
+type Client struct {
+	cfg runagent.Config
+
+	mu      sync.Mutex
+	clients map[string]*runagent.RunAgentClient
+}
+
+// New returns a Client. An empty cfg.AgentID defaults to AgentID;
+// cfg.EntrypointTag is set per method.
+func New(cfg runagent.Config) *Client {
+	if cfg.AgentID == "" {
+		cfg.AgentID = AgentID
+	}
+	return &Client{cfg: cfg, clients: map[string]*runagent.RunAgentClient{}}
+}
+
+func (c *Client) client(tag string) (*runagent.RunAgentClient, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if client, ok := c.clients[tag]; ok {
+		return client, nil
+	}
+	cfg := c.cfg
+	cfg.EntrypointTag = tag
+	client, err := runagent.NewRunAgentClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	c.clients[tag] = client
+	return client, nil
+}
+
+`
+
+type generator struct {
+	methods bytes.Buffer
+	types   bytes.Buffer
+	names   map[string]bool
+	structs map[string]bool
+	// pending holds nested structs, emitted after their parent.
+	pending []func()
+}
+
+// unique reserves a top-level identifier, suffixing a number on collision.
+func (g *generator) unique(name string) string {
+	candidate := name
+	for i := 2; g.names[candidate]; i++ {
+		candidate = fmt.Sprintf("%s%d", name, i)
+	}
+	g.names[candidate] = true
+	return candidate
+}
+
+func (g *generator) entrypoint(ep runagent.EntryPoint) {
+	method := g.unique(goName(ep.Tag))
+	stream := ep.Streaming()
+
+	var doc strings.Builder
+	if stream {
+		fmt.Fprintf(&doc, "// %s starts the %q stream entrypoint.", method, ep.Tag)
+	} else {
+		fmt.Fprintf(&doc, "// %s runs the %q entrypoint.", method, ep.Tag)
+	}
+	if ep.Description != "" {
+		doc.WriteString("\n//\n")
+		writeComment(&doc, "", ep.Description)
+	}
+	if ep.File != "" || ep.Module != "" {
+		fmt.Fprintf(&doc, "\n//\n// Implemented by %s.", strings.Trim(ep.File+":"+ep.Module, ":"))
+	}
+
+	result, call := "interface{}", "Run"
+	if stream {
+		result, call = "*runagent.StreamIterator", "RunStream"
+	}
+
+	schema := ep.JSONSchema()
+	if props, _ := schema["properties"].(map[string]interface{}); len(props) > 0 {
+		input := g.unique(method + "Input")
+		g.structType(input, fmt.Sprintf("%s is the input of the %q entrypoint.", input, ep.Tag), schema)
+		for len(g.pending) > 0 {
+			next := g.pending[0]
+			g.pending = g.pending[1:]
+			next()
+		}
+		fmt.Fprintf(&g.methods, "%s\n//\n// Extra values such as runagent.IdempotencyKey are passed through.\n", doc.String())
+		fmt.Fprintf(&g.methods, "func (c *Client) %s(ctx context.Context, in %s, opts ...any) (%s, error) {\n", method, input, result)
+		fmt.Fprintf(&g.methods, "\tclient, err := c.client(%s)\n\tif err != nil {\n\t\treturn nil, err\n\t}\n", strconv.Quote(ep.Tag))
+		fmt.Fprintf(&g.methods, "\treturn client.%s(ctx, append([]any{in}, opts...)...)\n}\n\n", call)
+		return
+	}
+
+	fmt.Fprintf(&g.methods, "%s\n//\n// No input schema is declared; values are passed to %s unchanged.\n", doc.String(), "RunAgentClient."+call)
+	fmt.Fprintf(&g.methods, "func (c *Client) %s(ctx context.Context, values ...any) (%s, error) {\n", method, result)
+	fmt.Fprintf(&g.methods, "\tclient, err := c.client(%s)\n\tif err != nil {\n\t\treturn nil, err\n\t}\n", strconv.Quote(ep.Tag))
+	fmt.Fprintf(&g.methods, "\treturn client.%s(ctx, values...)\n}\n\n", call)
+}
+
+// structType emits a struct for an object schema, nesting named types for
+// object properties that declare their own properties.
+func (g *generator) structType(name, doc string, schema map[string]interface{}) {
+	props, _ := schema["properties"].(map[string]interface{})
+	required := map[string]bool{}
+	if list, ok := schema["required"].([]interface{}); ok {
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var body bytes.Buffer
+	fields := map[string]bool{}
+	for _, key := range keys {
+		prop, _ := props[key].(map[string]interface{})
+		field := goName(key)
+		for i := 2; fields[field]; i++ {
+			field = fmt.Sprintf("%s%d", goName(key), i)
+		}
+		fields[field] = true
+
+		goType := g.goType(name+field, prop)
+		tag := key
+		if !required[key] {
+			tag += ",omitempty"
+			if isScalar(goType) || g.structs[goType] {
+				goType = "*" + goType
+			}
+		}
+
+		if desc, _ := prop["description"].(string); desc != "" {
+			writeComment(&body, "\t", desc)
+			body.WriteString("\n")
+		}
+		if enum, ok := prop["enum"].([]interface{}); ok && len(enum) > 0 {
+			values := make([]string, len(enum))
+			for i, v := range enum {
+				values[i] = fmt.Sprint(v)
+			}
+			fmt.Fprintf(&body, "\t// One of: %s.\n", strings.Join(values, ", "))
+		}
+		fmt.Fprintf(&body, "\t%s %s `json:%s`\n", field, goType, strconv.Quote(tag))
+	}
+
+	fmt.Fprintf(&g.types, "// %s\ntype %s struct {\n%s}\n\n", doc, name, body.String())
+}
+
+func (g *generator) goType(nested string, prop map[string]interface{}) string {
+	switch schemaType(prop) {
+	case "string":
+		return "string"
+	case "integer":
+		return "int64"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		items, _ := prop["items"].(map[string]interface{})
+		if items == nil {
+			return "[]interface{}"
+		}
+		return "[]" + g.goType(nested+"Item", items)
+	case "object":
+		if props, _ := prop["properties"].(map[string]interface{}); len(props) > 0 {
+			name := g.unique(nested)
+			g.structs[name] = true
+			g.pending = append(g.pending, func() {
+				g.structType(name, name+" is a nested input object.", prop)
+			})
+			return name
+		}
+		return "map[string]interface{}"
+	default:
+		return "interface{}"
+	}
+}
+
+// schemaType returns the single non-null type of a schema, if any.
+func schemaType(prop map[string]interface{}) string {
+	switch t := prop["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		var found string
+		for _, item := range t {
+			if s, ok := item.(string); ok && s != "null" {
+				if found != "" {
+					return ""
+				}
+				found = s
+			}
+		}
+		return found
+	}
+	if _, ok := prop["properties"]; ok {
+		return "object"
+	}
+	return ""
+}
+
+func isScalar(goType string) bool {
+	switch goType {
+	case "string", "int64", "float64", "bool":
+		return true
+	}
+	return false
+}
+
+// goName converts snake, kebab or dotted names to an exported identifier.
+func goName(s string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	name := b.String()
+	for _, initialism := range []string{"Id", "Url", "Api", "Json", "Http"} {
+		if strings.HasSuffix(name, initialism) {
+			name = strings.TrimSuffix(name, initialism) + strings.ToUpper(initialism)
+		}
+	}
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "X" + name
+	}
+	return name
+}
+
+func isIdentifier(s string) bool {
+	for i, r := range s {
+		if !unicode.IsLetter(r) && r != '_' && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// writeComment writes text as // comment lines with the given indent.
+func writeComment(b interface{ WriteString(string) (int, error) }, indent, text string) {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(strings.TrimRight(indent+"// "+strings.TrimSpace(line), " "))
+	}
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
@@ -0,0 +1,55 @@
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/runagent-dev/runagent-go"
+	"github.com/runagent-dev/runagent-go/internal/constants"
+)
+
+// ReadArchitecture loads an architecture from a saved JSON file. It accepts
+// the bare architecture, the service's {"data": ...} envelope, or an agent's
+// runagent.config.json; a directory is searched for runagent.config.json.
+func ReadArchitecture(path string) (*runagent.AgentArchitecture, error) {
+	if info, err := os.Stat(path); (err == nil && info.IsDir()) || filepath.Base(path) == constants.AgentConfigFileName {
+		cfg, err := runagent.LoadAgentConfig(path)
+		if err != nil {
+			return nil, err
+		}
+		if len(cfg.AgentArchitecture.Entrypoints) == 0 {
+			return nil, fmt.Errorf("%s: no entrypoints found", cfg.Path)
+		}
+		return cfg.Architecture(), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc struct {
+		AgentID           string                      `json:"agent_id"`
+		Entrypoints       []runagent.EntryPoint       `json:"entrypoints"`
+		Data              *runagent.AgentArchitecture `json:"data"`
+		AgentArchitecture *struct {
+			Entrypoints []runagent.EntryPoint `json:"entrypoints"`
+		} `json:"agent_architecture"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	arch := &runagent.AgentArchitecture{AgentID: doc.AgentID, Entrypoints: doc.Entrypoints}
+	switch {
+	case doc.AgentArchitecture != nil:
+		arch.Entrypoints = doc.AgentArchitecture.Entrypoints
+	case doc.Data != nil:
+		arch = doc.Data
+	}
+	if len(arch.Entrypoints) == 0 {
+		return nil, fmt.Errorf("%s: no entrypoints found", path)
+	}
+	return arch, nil
+}
@@ -12,9 +12,28 @@ import (
 
 // Config holds the SDK configuration
 type Config struct {
-	APIKey   string                 `json:"api_key,omitempty"`
-	BaseURL  string                 `json:"base_url"`
-	UserInfo map[string]interface{} `json:"user_info"`
+	APIKey         string                 `json:"api_key,omitempty"`
+	BaseURL        string                 `json:"base_url"`
+	UserInfo       map[string]interface{} `json:"user_info"`
+	DefaultProfile string                 `json:"default_profile,omitempty"`
+	Profiles       map[string]*Profile    `json:"profiles,omitempty"`
+}
+
+// Profile holds per-environment settings such as dev, staging or prod
+type Profile struct {
+	BaseURL        string `json:"base_url,omitempty"`
+	APIKey         string `json:"api_key,omitempty"`
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty"`
+	Local          *bool  `json:"local,omitempty"`
+}
+
+// LoadFile loads the config file without applying environment overrides
+func LoadFile() (*Config, error) {
+	config := &Config{UserInfo: make(map[string]interface{})}
+	if err := config.loadFromFile(); err != nil {
+		return nil, err
+	}
+	return config, nil
 }
 
 // Load loads configuration from various sources
@@ -99,6 +118,41 @@ func (c *Config) SetBaseURL(baseURL string) {
 	c.BaseURL = baseURL
 }
 
+// SetProfile creates or replaces a named profile
+func (c *Config) SetProfile(name string, profile *Profile) {
+	if c.Profiles == nil {
+		c.Profiles = make(map[string]*Profile)
+	}
+	c.Profiles[name] = profile
+}
+
+// DeleteProfile removes a named profile
+func (c *Config) DeleteProfile(name string) {
+	delete(c.Profiles, name)
+	if c.DefaultProfile == name {
+		c.DefaultProfile = ""
+	}
+}
+
+// ResolveProfile returns the named profile. An empty name falls back to
+// default_profile; when none is set, or it names a profile that no longer
+// exists, the top-level api_key and base_url act as an implicit profile.
+// Only an explicitly named profile that is missing is an error.
+func (c *Config) ResolveProfile(name string) (*Profile, string, error) {
+	if name == "" {
+		if profile, ok := c.Profiles[c.DefaultProfile]; ok && profile != nil {
+			return profile, c.DefaultProfile, nil
+		}
+		return &Profile{APIKey: c.APIKey, BaseURL: c.BaseURL}, "", nil
+	}
+
+	profile, ok := c.Profiles[name]
+	if !ok || profile == nil {
+		return nil, name, fmt.Errorf("profile %q not found in %s", name, c.getConfigFilePath())
+	}
+	return profile, name, nil
+}
+
 // IsConfigured checks if the SDK is properly configured
 func (c *Config) IsConfigured() bool {
 	return c.APIKey != "" && c.BaseURL != ""
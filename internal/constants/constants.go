@@ -22,6 +22,12 @@ const (
 	EnvAgentHost  = "RUNAGENT_HOST"
 	EnvAgentPort  = "RUNAGENT_PORT"
 	EnvTimeout    = "RUNAGENT_TIMEOUT"
+	EnvTransport  = "RUNAGENT_STREAM_TRANSPORT"
+	EnvProfile    = "RUNAGENT_PROFILE"
+	EnvCAFile     = "RUNAGENT_CA_FILE"
+	EnvClientCert = "RUNAGENT_CLIENT_CERT"
+	EnvClientKey  = "RUNAGENT_CLIENT_KEY"
+	EnvSocketPath = "RUNAGENT_SOCKET"
 
 	// Default values
 	DefaultBaseURL        = "https://backend.run-agent.ai"
@@ -35,13 +41,15 @@ const (
 	AgentConfigFileName   = "runagent.config.json"
 	UserDataFileName      = "user_data.json"
 	DatabaseFileName      = "runagent_local.db"
+	SocketDirName         = "sockets"
 
 	// Port configuration
 	DefaultPortStart = 8450
 	DefaultPortEnd   = 8500
 
 	// Limits
-	MaxLocalAgents = 5
+	MaxLocalAgents            = 5
+	DefaultMaxAttachmentBytes = 50 << 20
 )
 
 // GetLocalCacheDirectory returns the local cache directory path
@@ -63,6 +71,11 @@ func GetDatabasePath() string {
 	return filepath.Join(GetLocalCacheDirectory(), DatabaseFileName)
 }
 
+// GetAgentSocketPath returns the default Unix socket path for a local agent
+func GetAgentSocketPath(agentID string) string {
+	return filepath.Join(GetLocalCacheDirectory(), SocketDirName, agentID+".sock")
+}
+
 // Framework represents supported AI frameworks
 type Framework string
 
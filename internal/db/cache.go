@@ -0,0 +1,63 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// GetCacheEntry returns the cached value for key, or ok=false when it is
+// missing or expired
+func (s *Service) GetCacheEntry(key string) (value []byte, ok bool, err error) {
+	var expiresAt sql.NullTime
+	err = s.db.QueryRow(
+		`SELECT value, expires_at FROM cache_entries WHERE cache_key = ?`, key,
+	).Scan(&value, &expiresAt)
+	if err == sql.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+	if expiresAt.Valid && time.Now().After(expiresAt.Time) {
+		return nil, false, s.DeleteCacheEntry(key)
+	}
+	return value, true, nil
+}
+
+// SetCacheEntry stores value under key; a zero ttl never expires
+func (s *Service) SetCacheEntry(key string, value []byte, ttl time.Duration) error {
+	var expiresAt *time.Time
+	if ttl > 0 {
+		at := time.Now().Add(ttl)
+		expiresAt = &at
+	}
+
+	_, err := s.db.Exec(
+		`INSERT OR REPLACE INTO cache_entries (cache_key, value, expires_at, created_at) VALUES (?, ?, ?, ?)`,
+		key, value, expiresAt, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// DeleteCacheEntry removes a cache entry
+func (s *Service) DeleteCacheEntry(key string) error {
+	if _, err := s.db.Exec(`DELETE FROM cache_entries WHERE cache_key = ?`, key); err != nil {
+		return fmt.Errorf("failed to delete cache entry: %w", err)
+	}
+	return nil
+}
+
+// PurgeExpiredCacheEntries removes expired cache entries and returns how many were deleted
+func (s *Service) PurgeExpiredCacheEntries() (int64, error) {
+	result, err := s.db.Exec(
+		`DELETE FROM cache_entries WHERE expires_at IS NOT NULL AND expires_at < ?`, time.Now(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge cache entries: %w", err)
+	}
+	return result.RowsAffected()
+}
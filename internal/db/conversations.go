@@ -0,0 +1,224 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Conversation represents a persisted multi-turn conversation
+type Conversation struct {
+	ThreadID       string    `json:"thread_id"`
+	AgentID        string    `json:"agent_id"`
+	ParentThreadID *string   `json:"parent_thread_id,omitempty"`
+	MessageCount   int       `json:"message_count"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ConversationMessage represents a single transcript entry
+type ConversationMessage struct {
+	ID        int64     `json:"id"`
+	ThreadID  string    `json:"thread_id"`
+	Role      string    `json:"role"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateConversation inserts a conversation if it does not exist yet
+func (s *Service) CreateConversation(conv *Conversation) error {
+	now := time.Now()
+	if conv.CreatedAt.IsZero() {
+		conv.CreatedAt = now
+	}
+	if conv.UpdatedAt.IsZero() {
+		conv.UpdatedAt = now
+	}
+
+	query := `INSERT OR IGNORE INTO conversations (
+		thread_id, agent_id, parent_thread_id, created_at, updated_at
+	) VALUES (?, ?, ?, ?, ?)`
+
+	_, err := s.db.Exec(query,
+		conv.ThreadID, conv.AgentID, conv.ParentThreadID,
+		conv.CreatedAt, conv.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert conversation: %w", err)
+	}
+	return nil
+}
+
+// GetConversation retrieves a conversation by thread ID
+func (s *Service) GetConversation(threadID string) (*Conversation, error) {
+	query := `SELECT c.thread_id, c.agent_id, c.parent_thread_id, c.created_at, c.updated_at,
+		(SELECT COUNT(*) FROM conversation_messages m WHERE m.thread_id = c.thread_id)
+		FROM conversations c WHERE c.thread_id = ?`
+
+	conv, err := scanConversation(s.db.QueryRow(query, threadID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get conversation: %w", err)
+	}
+	return conv, nil
+}
+
+// ListConversations returns conversations for an agent, most recent first.
+// An empty agentID lists conversations for all agents.
+func (s *Service) ListConversations(agentID string) ([]*Conversation, error) {
+	query := `SELECT c.thread_id, c.agent_id, c.parent_thread_id, c.created_at, c.updated_at,
+		(SELECT COUNT(*) FROM conversation_messages m WHERE m.thread_id = c.thread_id)
+		FROM conversations c WHERE (? = '' OR c.agent_id = ?) ORDER BY c.updated_at DESC`
+
+	rows, err := s.db.Query(query, agentID, agentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query conversations: %w", err)
+	}
+	defer rows.Close()
+
+	var conversations []*Conversation
+	for rows.Next() {
+		conv, err := scanConversation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan conversation: %w", err)
+		}
+		conversations = append(conversations, conv)
+	}
+
+	return conversations, rows.Err()
+}
+
+// AddConversationMessages appends messages to their conversation
+// transcripts in one transaction, so either all of them are stored or none
+func (s *Service) AddConversationMessages(msgs ...*ConversationMessage) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, msg := range msgs {
+		if msg.CreatedAt.IsZero() {
+			msg.CreatedAt = time.Now()
+		}
+		result, err := tx.Exec(
+			`INSERT INTO conversation_messages (thread_id, role, content, created_at) VALUES (?, ?, ?, ?)`,
+			msg.ThreadID, msg.Role, msg.Content, msg.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert conversation message: %w", err)
+		}
+		if msg.ID, err = result.LastInsertId(); err != nil {
+			return fmt.Errorf("failed to read message id: %w", err)
+		}
+
+		if _, err := tx.Exec(`UPDATE conversations SET updated_at = ? WHERE thread_id = ?`, msg.CreatedAt, msg.ThreadID); err != nil {
+			return fmt.Errorf("failed to update conversation: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetConversationMessages returns the transcript of a conversation in order
+func (s *Service) GetConversationMessages(threadID string) ([]*ConversationMessage, error) {
+	query := `SELECT id, thread_id, role, content, created_at
+		FROM conversation_messages WHERE thread_id = ? ORDER BY id ASC`
+
+	rows, err := s.db.Query(query, threadID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query conversation messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*ConversationMessage
+	for rows.Next() {
+		var msg ConversationMessage
+		if err := rows.Scan(&msg.ID, &msg.ThreadID, &msg.Role, &msg.Content, &msg.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan conversation message: %w", err)
+		}
+		messages = append(messages, &msg)
+	}
+
+	return messages, rows.Err()
+}
+
+// ForkConversation copies a conversation and its transcript under a new thread ID
+func (s *Service) ForkConversation(sourceThreadID, newThreadID string) (*Conversation, error) {
+	source, err := s.GetConversation(sourceThreadID)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return nil, fmt.Errorf("conversation %s not found", sourceThreadID)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	_, err = tx.Exec(
+		`INSERT INTO conversations (thread_id, agent_id, parent_thread_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+		newThreadID, source.AgentID, sourceThreadID, now, now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert forked conversation: %w", err)
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO conversation_messages (thread_id, role, content, created_at)
+		SELECT ?, role, content, created_at FROM conversation_messages WHERE thread_id = ? ORDER BY id ASC`,
+		newThreadID, sourceThreadID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy conversation messages: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit fork: %w", err)
+	}
+
+	return s.GetConversation(newThreadID)
+}
+
+// DeleteConversation removes a conversation and its transcript
+func (s *Service) DeleteConversation(threadID string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM conversation_messages WHERE thread_id = ?`, threadID); err != nil {
+		return fmt.Errorf("failed to delete conversation messages: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM conversations WHERE thread_id = ?`, threadID); err != nil {
+		return fmt.Errorf("failed to delete conversation: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanConversation(row rowScanner) (*Conversation, error) {
+	var conv Conversation
+	var parent sql.NullString
+	if err := row.Scan(
+		&conv.ThreadID, &conv.AgentID, &parent,
+		&conv.CreatedAt, &conv.UpdatedAt, &conv.MessageCount,
+	); err != nil {
+		return nil, err
+	}
+	if parent.Valid {
+		conv.ParentThreadID = &parent.String
+	}
+	return &conv, nil
+}
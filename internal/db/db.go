@@ -17,6 +17,7 @@ type Agent struct {
 	AgentPath    string     `json:"agent_path"`
 	Host         string     `json:"host"`
 	Port         int        `json:"port"`
+	SocketPath   string     `json:"socket_path,omitempty"`
 	Framework    string     `json:"framework"`
 	Status       string     `json:"status"`
 	DeployedAt   time.Time  `json:"deployed_at"`
@@ -109,6 +110,7 @@ func (s *Service) createTables() error {
 			agent_path TEXT NOT NULL,
 			host TEXT NOT NULL DEFAULT 'localhost',
 			port INTEGER NOT NULL DEFAULT 8450,
+			socket_path TEXT,
 			framework TEXT,
 			status TEXT NOT NULL DEFAULT 'deployed',
 			deployed_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
@@ -131,9 +133,33 @@ func (s *Service) createTables() error {
 			completed_at DATETIME,
 			FOREIGN KEY (agent_id) REFERENCES agents(agent_id) ON DELETE CASCADE
 		)`,
+		`CREATE TABLE IF NOT EXISTS conversations (
+			thread_id TEXT PRIMARY KEY,
+			agent_id TEXT NOT NULL,
+			parent_thread_id TEXT,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS conversation_messages (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			thread_id TEXT NOT NULL,
+			role TEXT NOT NULL,
+			content TEXT NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (thread_id) REFERENCES conversations(thread_id) ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS cache_entries (
+			cache_key TEXT PRIMARY KEY,
+			value BLOB NOT NULL,
+			expires_at DATETIME,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
 		`CREATE INDEX IF NOT EXISTS idx_agents_status ON agents(status)`,
 		`CREATE INDEX IF NOT EXISTS idx_agent_runs_agent_id ON agent_runs(agent_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_agent_runs_started_at ON agent_runs(started_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_conversations_agent_id ON conversations(agent_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_conversation_messages_thread_id ON conversation_messages(thread_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_cache_entries_expires_at ON cache_entries(expires_at)`,
 	}
 
 	for _, query := range queries {
@@ -142,6 +168,38 @@ func (s *Service) createTables() error {
 		}
 	}
 
+	return s.addColumnIfMissing("agents", "socket_path", "TEXT")
+}
+
+// addColumnIfMissing upgrades tables created by older versions
+func (s *Service) addColumnIfMissing(table, column, definition string) error {
+	rows, err := s.db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("failed to inspect table %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return fmt.Errorf("failed to inspect table %s: %w", table, err)
+		}
+		if name == column {
+			return nil
+		}
+	}
+	rows.Close()
+
+	if _, err := s.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("failed to add column %s.%s: %w", table, column, err)
+	}
 	return nil
 }
 
@@ -187,12 +245,12 @@ func (s *Service) AddAgent(agent *Agent) (*AddAgentResult, error) {
 
 	// Insert agent
 	query := `INSERT INTO agents (
-		agent_id, agent_path, host, port, framework, status,
+		agent_id, agent_path, host, port, socket_path, framework, status,
 		deployed_at, created_at, updated_at
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	_, err = s.db.Exec(query,
-		agent.AgentID, agent.AgentPath, agent.Host, agent.Port,
+		agent.AgentID, agent.AgentPath, agent.Host, agent.Port, nullableString(agent.SocketPath),
 		agent.Framework, agent.Status, agent.DeployedAt,
 		agent.CreatedAt, agent.UpdatedAt,
 	)
@@ -208,13 +266,13 @@ func (s *Service) AddAgent(agent *Agent) (*AddAgentResult, error) {
 		APICheckPerformed: false,
 		AllocatedHost:     agent.Host,
 		AllocatedPort:     agent.Port,
-		Address:           fmt.Sprintf("%s:%d", agent.Host, agent.Port),
+		Address:           agentAddress(agent),
 	}, nil
 }
 
 // GetAgent retrieves an agent by ID
 func (s *Service) GetAgent(agentID string) (*Agent, error) {
-	query := `SELECT agent_id, agent_path, host, port, framework, status,
+	query := `SELECT agent_id, agent_path, host, port, COALESCE(socket_path, ''), framework, status,
 		deployed_at, last_run, run_count, success_count, error_count,
 		created_at, updated_at FROM agents WHERE agent_id = ?`
 
@@ -222,7 +280,7 @@ func (s *Service) GetAgent(agentID string) (*Agent, error) {
 	var lastRun sql.NullTime
 
 	err := s.db.QueryRow(query, agentID).Scan(
-		&agent.AgentID, &agent.AgentPath, &agent.Host, &agent.Port,
+		&agent.AgentID, &agent.AgentPath, &agent.Host, &agent.Port, &agent.SocketPath,
 		&agent.Framework, &agent.Status, &agent.DeployedAt, &lastRun,
 		&agent.RunCount, &agent.SuccessCount, &agent.ErrorCount,
 		&agent.CreatedAt, &agent.UpdatedAt,
@@ -243,7 +301,7 @@ func (s *Service) GetAgent(agentID string) (*Agent, error) {
 
 // ListAgents returns all agents
 func (s *Service) ListAgents() ([]*Agent, error) {
-	query := `SELECT agent_id, agent_path, host, port, framework, status,
+	query := `SELECT agent_id, agent_path, host, port, COALESCE(socket_path, ''), framework, status,
 		deployed_at, last_run, run_count, success_count, error_count,
 		created_at, updated_at FROM agents ORDER BY deployed_at DESC`
 
@@ -259,7 +317,7 @@ func (s *Service) ListAgents() ([]*Agent, error) {
 		var lastRun sql.NullTime
 
 		err := rows.Scan(
-			&agent.AgentID, &agent.AgentPath, &agent.Host, &agent.Port,
+			&agent.AgentID, &agent.AgentPath, &agent.Host, &agent.Port, &agent.SocketPath,
 			&agent.Framework, &agent.Status, &agent.DeployedAt, &lastRun,
 			&agent.RunCount, &agent.SuccessCount, &agent.ErrorCount,
 			&agent.CreatedAt, &agent.UpdatedAt,
@@ -278,6 +336,56 @@ func (s *Service) ListAgents() ([]*Agent, error) {
 	return agents, nil
 }
 
+// RemoveAgent deletes an agent and its run history
+func (s *Service) RemoveAgent(agentID string) (bool, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM agent_runs WHERE agent_id = ?`, agentID); err != nil {
+		return false, fmt.Errorf("failed to delete agent runs: %w", err)
+	}
+	result, err := tx.Exec(`DELETE FROM agents WHERE agent_id = ?`, agentID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete agent: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete agent: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit agent removal: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// SetAgentSocketPath records the Unix socket a local agent listens on; an
+// empty path clears it
+func (s *Service) SetAgentSocketPath(agentID, socketPath string) error {
+	query := `UPDATE agents SET socket_path = ?, updated_at = ? WHERE agent_id = ?`
+	if _, err := s.db.Exec(query, nullableString(socketPath), time.Now(), agentID); err != nil {
+		return fmt.Errorf("failed to update agent socket: %w", err)
+	}
+	return nil
+}
+
+// agentAddress renders the address an agent is reachable at
+func agentAddress(agent *Agent) string {
+	if agent.SocketPath != "" {
+		return "unix://" + agent.SocketPath
+	}
+	return fmt.Sprintf("%s:%d", agent.Host, agent.Port)
+}
+
+func nullableString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
 // GetCapacityInfo returns database capacity information
 func (s *Service) GetCapacityInfo() (*CapacityInfo, error) {
 	currentCount, err := s.getAgentCount()
@@ -300,6 +408,9 @@ func (s *Service) GetCapacityInfo() (*CapacityInfo, error) {
 			"status":      agent.Status,
 			"deployed_at": agent.DeployedAt,
 		}
+		if agent.SocketPath != "" {
+			agentMaps[i]["socket_path"] = agent.SocketPath
+		}
 	}
 
 	defaultLimit := constants.MaxLocalAgents
@@ -360,3 +471,62 @@ func (s *Service) RecordAgentRun(run *AgentRun) error {
 
 	return nil
 }
+
+// ListAgentRuns returns the most recent runs, newest first. An empty agentID
+// lists runs for all agents; limit <= 0 returns every run.
+func (s *Service) ListAgentRuns(agentID string, limit int) ([]*AgentRun, error) {
+	query := `SELECT id, agent_id, input_data, output_data, success, error_message,
+		execution_time, started_at, completed_at FROM agent_runs`
+	var args []interface{}
+	if agentID != "" {
+		query += ` WHERE agent_id = ?`
+		args = append(args, agentID)
+	}
+	query += ` ORDER BY started_at DESC, id DESC`
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query agent runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []*AgentRun
+	for rows.Next() {
+		var run AgentRun
+		var outputData, errorMessage sql.NullString
+		var executionTime sql.NullFloat64
+		var completedAt sql.NullTime
+
+		err := rows.Scan(
+			&run.ID, &run.AgentID, &run.InputData, &outputData, &run.Success,
+			&errorMessage, &executionTime, &run.StartedAt, &completedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan agent run: %w", err)
+		}
+
+		if outputData.Valid {
+			run.OutputData = &outputData.String
+		}
+		if errorMessage.Valid {
+			run.ErrorMessage = &errorMessage.String
+		}
+		if executionTime.Valid {
+			run.ExecutionTime = &executionTime.Float64
+		}
+		if completedAt.Valid {
+			run.CompletedAt = &completedAt.Time
+		}
+
+		runs = append(runs, &run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read agent runs: %w", err)
+	}
+
+	return runs, nil
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"crypto/sha256"
+	"sync"
+	"time"
+)
+
+// idempotencyTTL bounds how long completed responses are replayed. Server
+// errors are not stored: the key is released so a retry runs again.
+const idempotencyTTL = 24 * time.Hour
+
+// idempotencyStore deduplicates requests that carry an Idempotency-Key.
+type idempotencyStore struct {
+	mu      sync.Mutex
+	entries map[string]*idempotencyEntry
+}
+
+type idempotencyEntry struct {
+	fingerprint [sha256.Size]byte
+	done        chan struct{}
+	status      int
+	body        []byte
+	completedAt time.Time
+	// released is set when the run failed without a replayable response;
+	// waiters must claim the key again.
+	released bool
+}
+
+func newIdempotencyStore() *idempotencyStore {
+	return &idempotencyStore{entries: map[string]*idempotencyEntry{}}
+}
+
+// begin claims the key for a request body. It returns the entry and whether
+// the caller owns it and must call finish. conflict is true when the key was
+// already used with a different body.
+func (s *idempotencyStore) begin(key string, body []byte) (entry *idempotencyEntry, owner bool, conflict bool) {
+	fingerprint := sha256.Sum256(body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pruneLocked()
+	if existing, ok := s.entries[key]; ok {
+		if existing.fingerprint != fingerprint {
+			return nil, false, true
+		}
+		return existing, false, false
+	}
+
+	entry = &idempotencyEntry{
+		fingerprint: fingerprint,
+		done:        make(chan struct{}),
+	}
+	s.entries[key] = entry
+	return entry, true, false
+}
+
+// finish stores the response and wakes requests waiting on the key. A 5xx
+// response is not stored; the key is released instead.
+func (s *idempotencyStore) finish(key string, entry *idempotencyEntry, status int, body []byte) {
+	if status >= 500 {
+		s.release(key, entry)
+		return
+	}
+	s.mu.Lock()
+	entry.status = status
+	entry.body = body
+	entry.completedAt = time.Now()
+	s.mu.Unlock()
+	close(entry.done)
+}
+
+// release forgets an unfinished claim, e.g. after a server error or an
+// aborted handler, so the next request with the key runs the entrypoint.
+func (s *idempotencyStore) release(key string, entry *idempotencyEntry) {
+	s.mu.Lock()
+	if s.entries[key] == entry {
+		delete(s.entries, key)
+	}
+	entry.released = true
+	s.mu.Unlock()
+	close(entry.done)
+}
+
+func (s *idempotencyStore) pruneLocked() {
+	for key, entry := range s.entries {
+		if !entry.completedAt.IsZero() && time.Since(entry.completedAt) > idempotencyTTL {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -4,12 +4,19 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/runagent-dev/runagent-go/internal/constants"
+	"github.com/runagent-dev/runagent-go/internal/db"
 	"github.com/runagent-dev/runagent-go/internal/types"
+	"github.com/runagent-dev/runagent-go/internal/utils"
 )
 
 // Server represents a local RunAgent server
@@ -19,6 +26,12 @@ type Server struct {
 	host      string
 	port      int
 	server    *http.Server
+
+	// socketPath, when set, makes the server listen on a Unix socket
+	// instead of host:port.
+	socketPath string
+
+	idempotency *idempotencyStore
 }
 
 // New creates a new local server
@@ -28,6 +41,8 @@ func New(agentID, agentPath, host string, port int) (*Server, error) {
 		agentPath: agentPath,
 		host:      host,
 		port:      port,
+
+		idempotency: newIdempotencyStore(),
 	}
 
 	router := s.setupRoutes()
@@ -49,7 +64,8 @@ func (s *Server) setupRoutes() *mux.Router {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID, Idempotency-Key")
+			w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID, X-Run-ID, Idempotent-Replayed")
 
 			if r.Method == "OPTIONS" {
 				w.WriteHeader(http.StatusOK)
@@ -75,13 +91,84 @@ func (s *Server) setupRoutes() *mux.Router {
 	return router
 }
 
+// NewUnix creates a local server that listens on a Unix socket. An empty
+// socketPath uses the default path under the RunAgent cache directory.
+func NewUnix(agentID, agentPath, socketPath string) (*Server, error) {
+	if socketPath == "" {
+		socketPath = constants.GetAgentSocketPath(agentID)
+	}
+
+	s, err := New(agentID, agentPath, "", 0)
+	if err != nil {
+		return nil, err
+	}
+	s.socketPath = socketPath
+	s.server.Addr = ""
+	return s, nil
+}
+
 // Start starts the server
 func (s *Server) Start() error {
-	log.Printf("🚀 Starting local server on %s", s.server.Addr)
+	log.Printf("🚀 Starting local server on %s", s.Address())
 	log.Printf("🆔 Agent ID: %s", s.agentID)
 	log.Printf("📁 Agent Path: %s", s.agentPath)
 
-	return s.server.ListenAndServe()
+	if s.socketPath == "" {
+		return s.server.ListenAndServe()
+	}
+
+	listener, err := listenUnix(s.socketPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(s.socketPath)
+
+	// Record the socket so clients discovering the agent dial it, and clear
+	// it again once the server stops.
+	s.recordSocketPath(s.socketPath)
+	defer s.recordSocketPath("")
+	return s.server.Serve(listener)
+}
+
+// recordSocketPath stores the agent's socket in the local registry. Failures
+// are logged only: the server works without it, clients just cannot
+// discover the socket.
+func (s *Server) recordSocketPath(socketPath string) {
+	svc, err := db.NewService("")
+	if err != nil {
+		log.Printf("⚠️ Failed to open local registry: %v", err)
+		return
+	}
+	defer svc.Close()
+
+	if err := svc.SetAgentSocketPath(s.agentID, socketPath); err != nil {
+		log.Printf("⚠️ Failed to record agent socket: %v", err)
+	}
+}
+
+// listenUnix listens on socketPath, replacing a stale socket left behind by
+// a crashed server. The socket is only accessible to the current user.
+func listenUnix(socketPath string) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(socketPath), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	if conn, err := net.Dial("unix", socketPath); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("socket %s is already in use", socketPath)
+	}
+	if err := os.Remove(socketPath); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", socketPath, err)
+	}
+	if err := os.Chmod(socketPath, 0600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to restrict socket permissions: %w", err)
+	}
+	return listener, nil
 }
 
 // Shutdown gracefully shuts down the server
@@ -92,9 +179,17 @@ func (s *Server) Shutdown(ctx context.Context) error {
 
 // Address returns the server address
 func (s *Server) Address() string {
+	if s.socketPath != "" {
+		return "unix://" + s.socketPath
+	}
 	return s.server.Addr
 }
 
+// SocketPath returns the Unix socket path, or "" for TCP servers
+func (s *Server) SocketPath() string {
+	return s.socketPath
+}
+
 // handleRoot handles the root endpoint
 func (s *Server) handleRoot(w http.ResponseWriter, r *http.Request) {
 	info := types.AgentInfo{
@@ -169,12 +264,76 @@ func (s *Server) handleRunAgent(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	entrypoint := vars["entrypoint"]
 
+	requestID := r.Header.Get("X-Request-ID")
+	if requestID == "" {
+		requestID = utils.NewUUID()
+	}
+	w.Header().Set("X-Request-ID", requestID)
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
 	var request types.AgentRunRequest
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+	if err := json.Unmarshal(body, &request); err != nil {
 		http.Error(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
 		return
 	}
 
+	key := r.Header.Get("Idempotency-Key")
+	if key == "" {
+		status, response := s.executeRun(entrypoint, request)
+		writeRunResponse(w, status, response)
+		return
+	}
+
+	// Keys are scoped to the entrypoint so the same key on another tag is a new run.
+	key = entrypoint + "/" + key
+	for {
+		entry, owner, conflict := s.idempotency.begin(key, body)
+		if conflict {
+			http.Error(w, "Idempotency-Key was already used with a different request body", http.StatusUnprocessableEntity)
+			return
+		}
+		if owner {
+			s.runIdempotent(w, key, entry, entrypoint, request)
+			return
+		}
+		select {
+		case <-entry.done:
+		case <-r.Context().Done():
+			return
+		}
+		if entry.released {
+			// The original run failed; claim the key and run again.
+			continue
+		}
+		w.Header().Set("Idempotent-Replayed", "true")
+		writeRunResponse(w, entry.status, entry.body)
+		return
+	}
+}
+
+// runIdempotent executes a run that owns its idempotency key. The key is
+// released if the handler aborts before a response is stored.
+func (s *Server) runIdempotent(w http.ResponseWriter, key string, entry *idempotencyEntry, entrypoint string, request types.AgentRunRequest) {
+	finished := false
+	defer func() {
+		if !finished {
+			s.idempotency.release(key, entry)
+		}
+	}()
+
+	status, response := s.executeRun(entrypoint, request)
+	s.idempotency.finish(key, entry, status, response)
+	finished = true
+	writeRunResponse(w, status, response)
+}
+
+// executeRun runs the entrypoint and returns the encoded response.
+func (s *Server) executeRun(entrypoint string, request types.AgentRunRequest) (int, []byte) {
 	startTime := time.Now()
 
 	// Mock execution based on entrypoint
@@ -200,10 +359,26 @@ func (s *Server) handleRunAgent(w http.ResponseWriter, r *http.Request) {
 		Error:         errorMsg,
 		ExecutionTime: executionTime,
 		AgentID:       s.agentID,
+		RunID:         utils.NewUUID(),
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		return http.StatusInternalServerError, []byte(`{"success":false,"error":"failed to encode response"}`)
 	}
+	return http.StatusOK, body
+}
 
+func writeRunResponse(w http.ResponseWriter, status int, body []byte) {
+	var envelope struct {
+		RunID string `json:"run_id"`
+	}
+	if json.Unmarshal(body, &envelope) == nil && envelope.RunID != "" {
+		w.Header().Set("X-Run-ID", envelope.RunID)
+	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	w.WriteHeader(status)
+	w.Write(append(body, '\n'))
 }
 
 // executeGeneric executes the generic entrypoint
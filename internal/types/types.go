@@ -75,6 +75,7 @@ type AgentRunResponse struct {
 	Error         string      `json:"error,omitempty"`
 	ExecutionTime float64     `json:"execution_time,omitempty"`
 	AgentID       string      `json:"agent_id"`
+	RunID         string      `json:"run_id,omitempty"`
 }
 
 // AgentInfo represents agent information
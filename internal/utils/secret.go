@@ -0,0 +1,13 @@
+package utils
+
+// MaskSecret hides all but the last four characters of a secret. An empty
+// secret stays empty.
+func MaskSecret(s string) string {
+	if s == "" {
+		return ""
+	}
+	if len(s) <= 4 {
+		return "****"
+	}
+	return "****" + s[len(s)-4:]
+}
@@ -0,0 +1,542 @@
+package runagent
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Role identifies the author of a chat message.
+type Role string
+
+const (
+	RoleSystem    Role = "system"
+	RoleUser      Role = "user"
+	RoleAssistant Role = "assistant"
+	RoleTool      Role = "tool"
+)
+
+// Message is a framework-neutral chat message. Message and []Message values
+// passed to Run or RunStream are shaped for the agent's framework;
+// ParseMessages, RunAgentClient.Messages and StreamIterator.NextMessages
+// decode outputs and stream deltas back into messages.
+type Message struct {
+	Role    Role   `json:"role"`
+	Content string `json:"content"`
+	// Name identifies a participant, such as an AutoGen or CrewAI agent.
+	Name string `json:"name,omitempty"`
+	// ToolCalls are the tools an assistant message asks to run.
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	// ToolResult is set on tool messages answering a ToolCall.
+	ToolResult *ToolResult `json:"tool_result,omitempty"`
+	// Raw is the value the message was parsed from, if any.
+	Raw interface{} `json:"-"`
+}
+
+// ToolCall is a tool invocation requested by the model.
+type ToolCall struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+	// Arguments are the decoded arguments once they form a JSON object.
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+	// RawArguments holds arguments received as text. In stream deltas it
+	// is a fragment; Message.Append concatenates fragments.
+	RawArguments string `json:"raw_arguments,omitempty"`
+	// Index orders streamed tool call chunks that arrive without an ID.
+	Index int `json:"index,omitempty"`
+}
+
+// ToolResult is the output of a tool, sent back to the model.
+type ToolResult struct {
+	CallID  string `json:"call_id,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Content string `json:"content"`
+	IsError bool   `json:"is_error,omitempty"`
+}
+
+// SystemMessage returns a system message.
+func SystemMessage(content string) Message { return Message{Role: RoleSystem, Content: content} }
+
+// UserMessage returns a user message.
+func UserMessage(content string) Message { return Message{Role: RoleUser, Content: content} }
+
+// AssistantMessage returns an assistant message.
+func AssistantMessage(content string) Message { return Message{Role: RoleAssistant, Content: content} }
+
+// ToolMessage returns a tool message answering the call with callID.
+func ToolMessage(callID, content string) Message {
+	return Message{Role: RoleTool, Content: content, ToolResult: &ToolResult{CallID: callID, Content: content}}
+}
+
+// Append merges a stream delta into m. Content is concatenated and tool call
+// chunks are matched by ID, or by Index when the chunk has no ID, with their
+// argument fragments concatenated.
+func (m *Message) Append(delta Message) {
+	if m.Role == "" {
+		m.Role = delta.Role
+	}
+	if m.Name == "" {
+		m.Name = delta.Name
+	}
+	m.Content += delta.Content
+	for _, chunk := range delta.ToolCalls {
+		i := m.findToolCall(chunk)
+		if i < 0 {
+			m.ToolCalls = append(m.ToolCalls, chunk)
+			continue
+		}
+		call := &m.ToolCalls[i]
+		if call.ID == "" {
+			call.ID = chunk.ID
+		}
+		if call.Name == "" {
+			call.Name = chunk.Name
+		}
+		call.RawArguments += chunk.RawArguments
+		if chunk.Arguments != nil {
+			call.Arguments = chunk.Arguments
+		} else if args := decodeArguments(call.RawArguments); args != nil {
+			call.Arguments = args
+		}
+	}
+	if delta.ToolResult != nil {
+		m.ToolResult = delta.ToolResult
+	}
+}
+
+func (m *Message) findToolCall(chunk ToolCall) int {
+	for i := len(m.ToolCalls) - 1; i >= 0; i-- {
+		call := m.ToolCalls[i]
+		if chunk.ID != "" {
+			if call.ID == chunk.ID {
+				return i
+			}
+			continue
+		}
+		if call.Index == chunk.Index {
+			return i
+		}
+	}
+	return -1
+}
+
+// toMap renders the message in the OpenAI chat format, which LangChain,
+// LangGraph and AutoGen all accept as a message dict.
+func (m Message) toMap() map[string]interface{} {
+	out := map[string]interface{}{"role": string(m.Role), "content": m.Content}
+	name := m.Name
+	if len(m.ToolCalls) > 0 {
+		calls := make([]interface{}, len(m.ToolCalls))
+		for i, call := range m.ToolCalls {
+			calls[i] = map[string]interface{}{
+				"id":   call.ID,
+				"type": "function",
+				"function": map[string]interface{}{
+					"name":      call.Name,
+					"arguments": argumentsText(call),
+				},
+			}
+		}
+		out["tool_calls"] = calls
+	}
+	if r := m.ToolResult; r != nil {
+		out["tool_call_id"] = r.CallID
+		if m.Content == "" {
+			out["content"] = r.Content
+		}
+		name = firstNonEmpty(name, r.Name)
+	}
+	if name != "" {
+		out["name"] = name
+	}
+	return out
+}
+
+func messageMaps(messages []Message) []interface{} {
+	out := make([]interface{}, len(messages))
+	for i, m := range messages {
+		out[i] = m.toMap()
+	}
+	return out
+}
+
+func argumentsText(call ToolCall) string {
+	if call.RawArguments != "" {
+		return call.RawArguments
+	}
+	if call.Arguments == nil {
+		return "{}"
+	}
+	data, err := json.Marshal(call.Arguments)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// ParseMessages decodes a run output or stream chunk into messages. It
+// recognizes OpenAI chat messages and completion chunks, LangChain message
+// dicts, chunks and serialized messages, Anthropic content blocks and
+// AutoGen agentchat messages, alone, in a list or under a "messages" key.
+// Anything else becomes one assistant message holding the value in Raw.
+func ParseMessages(v interface{}) []Message {
+	if v == nil {
+		return nil
+	}
+	if msgs := parseKnownMessages(v); len(msgs) > 0 {
+		return msgs
+	}
+	return []Message{rawMessage(v)}
+}
+
+func parseKnownMessages(v interface{}) []Message {
+	switch t := v.(type) {
+	case Message:
+		return []Message{t}
+	case []Message:
+		return t
+	case string:
+		return []Message{{Role: RoleAssistant, Content: t, Raw: v}}
+	case []interface{}:
+		return parseMessageList(t)
+	case map[string]interface{}:
+		if msgs, ok := parseMessageValue(t); ok {
+			return msgs
+		}
+		return parseMessageList(t["messages"])
+	}
+	return nil
+}
+
+// parseMessageList parses the message dicts in a list, skipping the rest.
+func parseMessageList(v interface{}) []Message {
+	list, _ := v.([]interface{})
+	var out []Message
+	for _, item := range list {
+		if msgs, ok := parseMessageValue(item); ok {
+			out = append(out, msgs...)
+		}
+	}
+	return out
+}
+
+// messageParsers recognize one message format each, most specific first.
+var messageParsers = []func(map[string]interface{}) ([]Message, bool){
+	parseLangChainSerialized,
+	parseCompletionChunk,
+	parseAutoGenMessage,
+	parseRoleMessage,
+	parseContentOnly,
+}
+
+func parseMessageValue(v interface{}) ([]Message, bool) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	for _, parse := range messageParsers {
+		if msgs, ok := parse(m); ok {
+			return msgs, true
+		}
+	}
+	return nil, false
+}
+
+// parseLangChainSerialized reads dumpd() output:
+// {"lc": 1, "type": "constructor", "id": [..., "AIMessage"], "kwargs": {...}}.
+func parseLangChainSerialized(m map[string]interface{}) ([]Message, bool) {
+	kwargs, ok := m["kwargs"].(map[string]interface{})
+	ids, _ := m["id"].([]interface{})
+	if !ok || m["lc"] == nil || len(ids) == 0 {
+		return nil, false
+	}
+	class, _ := ids[len(ids)-1].(string)
+	fields := make(map[string]interface{}, len(kwargs)+1)
+	for k, v := range kwargs {
+		fields[k] = v
+	}
+	fields["type"] = class
+	msg, ok := roleMessage(fields, "")
+	if !ok {
+		return nil, false
+	}
+	msg.Raw = m
+	return []Message{msg}, true
+}
+
+// parseCompletionChunk reads OpenAI chat completions and their stream
+// chunks: {"choices": [{"message": {...}}]} or {"choices": [{"delta": {...}}]}.
+func parseCompletionChunk(m map[string]interface{}) ([]Message, bool) {
+	choices, ok := m["choices"].([]interface{})
+	if !ok {
+		return nil, false
+	}
+	var out []Message
+	for _, item := range choices {
+		choice, _ := item.(map[string]interface{})
+		body, ok := choice["delta"].(map[string]interface{})
+		if !ok {
+			body, ok = choice["message"].(map[string]interface{})
+		}
+		if !ok {
+			continue
+		}
+		if msg, ok := roleMessage(body, RoleAssistant); ok {
+			msg.Raw = m
+			out = append(out, msg)
+		}
+	}
+	return out, len(out) > 0
+}
+
+// parseAutoGenMessage reads agentchat messages, which name their author in
+// "source". Tool call requests and execution results carry lists.
+func parseAutoGenMessage(m map[string]interface{}) ([]Message, bool) {
+	source, ok := m["source"].(string)
+	if _, hasContent := m["content"]; !ok || !hasContent {
+		return nil, false
+	}
+	role := RoleAssistant
+	if source == "user" {
+		role = RoleUser
+	}
+
+	items, _ := m["content"].([]interface{})
+	switch stringField(m, "type") {
+	case "ToolCallRequestEvent":
+		msg := Message{Role: role, Name: source, Raw: m}
+		for i, item := range items {
+			call, _ := item.(map[string]interface{})
+			msg.ToolCalls = append(msg.ToolCalls, toolCall(call, i))
+		}
+		return []Message{msg}, true
+	case "ToolCallExecutionEvent":
+		var out []Message
+		for _, item := range items {
+			result, _ := item.(map[string]interface{})
+			isError, _ := result["is_error"].(bool)
+			content := contentText(result["content"])
+			out = append(out, Message{
+				Role:    RoleTool,
+				Content: content,
+				Name:    source,
+				ToolResult: &ToolResult{
+					CallID:  stringField(result, "call_id"),
+					Name:    stringField(result, "name"),
+					Content: content,
+					IsError: isError,
+				},
+				Raw: m,
+			})
+		}
+		return out, len(out) > 0
+	}
+	return []Message{{Role: role, Content: contentText(m["content"]), Name: source, Raw: m}}, true
+}
+
+func parseRoleMessage(m map[string]interface{}) ([]Message, bool) {
+	msg, ok := roleMessage(m, "")
+	if !ok {
+		return nil, false
+	}
+	msg.Raw = m
+	return []Message{msg}, true
+}
+
+// parseContentOnly treats a bare {"content": ...} chunk as assistant text.
+func parseContentOnly(m map[string]interface{}) ([]Message, bool) {
+	content, ok := m["content"]
+	if !ok {
+		return nil, false
+	}
+	msg := Message{Role: RoleAssistant, Raw: m}
+	applyContent(&msg, content)
+	return []Message{msg}, true
+}
+
+// roleMessage reads a message dict identified by "role" (OpenAI,
+// Anthropic) or a LangChain "type". Without either, defaultRole is used
+// when set.
+func roleMessage(m map[string]interface{}, defaultRole Role) (Message, bool) {
+	var role Role
+	if name := stringField(m, "role"); name != "" {
+		role = normalizeRole(name)
+	} else if name := stringField(m, "type"); name != "" {
+		// "type" also names content blocks and frames; only accept roles.
+		if role = normalizeRole(name); !knownRole(role) {
+			return Message{}, false
+		}
+	} else if defaultRole != "" {
+		role = defaultRole
+	} else {
+		return Message{}, false
+	}
+
+	_, hasContent := m["content"]
+	_, hasCalls := m["tool_calls"]
+	_, hasChunks := m["tool_call_chunks"]
+	_, hasFunction := m["function_call"]
+	if !hasContent && !hasCalls && !hasChunks && !hasFunction && defaultRole == "" {
+		return Message{}, false
+	}
+
+	msg := Message{Role: role, Name: stringField(m, "name")}
+	applyContent(&msg, m["content"])
+
+	calls, _ := m["tool_calls"].([]interface{})
+	if len(calls) == 0 {
+		calls, _ = m["tool_call_chunks"].([]interface{})
+	}
+	if len(calls) == 0 {
+		if extra, ok := m["additional_kwargs"].(map[string]interface{}); ok {
+			calls, _ = extra["tool_calls"].([]interface{})
+		}
+	}
+	for i, item := range calls {
+		call, _ := item.(map[string]interface{})
+		msg.ToolCalls = append(msg.ToolCalls, toolCall(call, i))
+	}
+	if fn, ok := m["function_call"].(map[string]interface{}); ok {
+		msg.ToolCalls = append(msg.ToolCalls, toolCall(fn, len(msg.ToolCalls)))
+	}
+
+	if callID := stringField(m, "tool_call_id"); callID != "" || (role == RoleTool && msg.ToolResult == nil) {
+		msg.ToolResult = &ToolResult{
+			CallID:  callID,
+			Name:    msg.Name,
+			Content: msg.Content,
+			IsError: stringField(m, "status") == "error",
+		}
+	}
+	return msg, true
+}
+
+// applyContent sets the message text, and reads Anthropic tool_use and
+// tool_result blocks when content is a list of blocks.
+func applyContent(msg *Message, content interface{}) {
+	blocks, ok := content.([]interface{})
+	if !ok {
+		msg.Content = contentText(content)
+		return
+	}
+	var text []string
+	for _, item := range blocks {
+		switch block := item.(type) {
+		case string:
+			text = append(text, block)
+		case map[string]interface{}:
+			switch stringField(block, "type") {
+			case "tool_use":
+				msg.ToolCalls = append(msg.ToolCalls, toolCall(block, len(msg.ToolCalls)))
+			case "tool_result":
+				isError, _ := block["is_error"].(bool)
+				msg.ToolResult = &ToolResult{
+					CallID:  stringField(block, "tool_use_id"),
+					Content: contentText(block["content"]),
+					IsError: isError,
+				}
+				msg.Role = RoleTool
+			default:
+				if s, ok := block["text"].(string); ok {
+					text = append(text, s)
+				}
+			}
+		}
+	}
+	msg.Content = strings.Join(text, "")
+	if msg.Content == "" && msg.ToolResult != nil {
+		msg.Content = msg.ToolResult.Content
+	}
+}
+
+// toolCall reads the tool call shapes in use: OpenAI {"id", "function":
+// {"name", "arguments"}}, LangChain {"id", "name", "args"}, Anthropic
+// {"id", "name", "input"} and AutoGen {"id", "name", "arguments"}.
+func toolCall(m map[string]interface{}, position int) ToolCall {
+	fields := m
+	if fn, ok := m["function"].(map[string]interface{}); ok {
+		fields = fn
+	}
+	call := ToolCall{ID: stringField(m, "id"), Name: stringField(fields, "name"), Index: position}
+	if index, ok := m["index"].(float64); ok {
+		call.Index = int(index)
+	}
+	for _, key := range []string{"arguments", "args", "input"} {
+		switch args := fields[key].(type) {
+		case map[string]interface{}:
+			call.Arguments = args
+		case string:
+			call.RawArguments = args
+			call.Arguments = decodeArguments(args)
+		default:
+			continue
+		}
+		break
+	}
+	return call
+}
+
+func decodeArguments(text string) map[string]interface{} {
+	var args map[string]interface{}
+	if err := json.Unmarshal([]byte(text), &args); err != nil {
+		return nil
+	}
+	return args
+}
+
+// rawMessage wraps an unrecognized value as assistant text.
+func rawMessage(v interface{}) Message {
+	return Message{Role: RoleAssistant, Content: contentText(v), Raw: v}
+}
+
+// normalizeRole maps framework role names onto Role.
+func normalizeRole(role string) Role {
+	switch strings.ToLower(role) {
+	case "user", "human", "humanmessage", "humanmessagechunk":
+		return RoleUser
+	case "assistant", "ai", "aimessage", "aimessagechunk", "model", "chatbot":
+		return RoleAssistant
+	case "system", "systemmessage", "systemmessagechunk", "developer":
+		return RoleSystem
+	case "tool", "function", "toolmessage", "toolmessagechunk", "functionmessage":
+		return RoleTool
+	}
+	return Role(strings.ToLower(role))
+}
+
+func knownRole(role Role) bool {
+	switch role {
+	case RoleSystem, RoleUser, RoleAssistant, RoleTool:
+		return true
+	}
+	return false
+}
+
+// contentText flattens message content: strings as-is, content blocks
+// ([{"type": "text", "text": ...}]) joined, anything else as JSON.
+func contentText(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case []interface{}:
+		var parts []string
+		for _, item := range t {
+			switch block := item.(type) {
+			case string:
+				parts = append(parts, block)
+			case map[string]interface{}:
+				if text, ok := block["text"].(string); ok {
+					parts = append(parts, text)
+				}
+			}
+		}
+		return strings.Join(parts, "")
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(data)
+	}
+}
@@ -0,0 +1,90 @@
+package runagent
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMessageAppendDeltas(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []string
+		want   Message
+	}{
+		{
+			name: "OpenAI completion chunks",
+			chunks: []string{
+				`{"choices": [{"delta": {"role": "assistant", "content": "Hel"}}]}`,
+				`{"choices": [{"delta": {"content": "lo"}}]}`,
+				`{"choices": [{"delta": {"tool_calls": [{"index": 0, "id": "call_1", "function": {"name": "search", "arguments": "{\"q\":"}}]}}]}`,
+				`{"choices": [{"delta": {"tool_calls": [{"index": 0, "function": {"arguments": "\"go\"}"}}]}}]}`,
+			},
+			want: Message{
+				Role:    RoleAssistant,
+				Content: "Hello",
+				ToolCalls: []ToolCall{{
+					ID:           "call_1",
+					Name:         "search",
+					Arguments:    map[string]interface{}{"q": "go"},
+					RawArguments: `{"q":"go"}`,
+				}},
+			},
+		},
+		{
+			name: "LangChain message chunks",
+			chunks: []string{
+				`{"type": "AIMessageChunk", "content": "Hi ", "tool_call_chunks": []}`,
+				`{"type": "AIMessageChunk", "content": "there", "tool_call_chunks": [{"id": "t1", "name": "calc", "args": "{\"x\":", "index": 0}]}`,
+				`{"type": "AIMessageChunk", "content": "", "tool_call_chunks": [{"args": "1}", "index": 0}]}`,
+			},
+			want: Message{
+				Role:    RoleAssistant,
+				Content: "Hi there",
+				ToolCalls: []ToolCall{{
+					ID:           "t1",
+					Name:         "calc",
+					Arguments:    map[string]interface{}{"x": 1.0},
+					RawArguments: `{"x":1}`,
+				}},
+			},
+		},
+		{
+			name: "AutoGen streaming events",
+			chunks: []string{
+				`{"type": "ModelClientStreamingChunkEvent", "source": "planner", "content": "Good "}`,
+				`{"type": "ModelClientStreamingChunkEvent", "source": "planner", "content": "day"}`,
+				`{"type": "ToolCallRequestEvent", "source": "planner", "content": [{"id": "c1", "name": "lookup", "arguments": "{\"k\": \"v\"}"}]}`,
+			},
+			want: Message{
+				Role:    RoleAssistant,
+				Name:    "planner",
+				Content: "Good day",
+				ToolCalls: []ToolCall{{
+					ID:           "c1",
+					Name:         "lookup",
+					Arguments:    map[string]interface{}{"k": "v"},
+					RawArguments: `{"k": "v"}`,
+				}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Message
+			for _, chunk := range tt.chunks {
+				var decoded interface{}
+				if err := json.Unmarshal([]byte(chunk), &decoded); err != nil {
+					t.Fatalf("invalid chunk %s: %v", chunk, err)
+				}
+				for _, delta := range ParseMessages(decoded) {
+					got.Append(delta)
+				}
+			}
+			got.Raw = nil
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("accumulated message = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
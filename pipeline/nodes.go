@@ -0,0 +1,399 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/runagent-dev/runagent-go"
+)
+
+// Node is a pipeline element: Step, Sequence, Parallel or Route.
+type Node interface {
+	nodeName() string
+	validate(names map[string]bool) error
+	run(ctx context.Context, x *execution, prev interface{}) (interface{}, error)
+}
+
+// DefaultRetryDelay is the delay before a step's first retry; it doubles on
+// each further attempt.
+const DefaultRetryDelay = 500 * time.Millisecond
+
+// Step runs one agent entrypoint. Stream entrypoints are consumed to the
+// end; string chunks are joined and anything else is returned as a list.
+type Step struct {
+	Name       string
+	AgentID    string
+	Entrypoint string
+	// Map builds the step's kwargs from the state. Nil passes the previous
+	// output: objects as kwargs, anything else as the single positional
+	// argument, and nil as no arguments.
+	Map func(s *State) (map[string]any, error)
+	// Config overrides Options.Config for this step.
+	Config *runagent.Config
+	// Timeout bounds each attempt. Zero relies on the client timeout.
+	Timeout time.Duration
+	// Retries is the number of extra attempts after a failure. All attempts
+	// share an idempotency key, so a server that completed the run replays
+	// its result instead of running the step twice. This relies on the
+	// server not storing 5xx responses under the key, as the local server
+	// does; one that does would answer every retry of a server error with
+	// the original failure.
+	Retries int
+	// RetryDelay is the delay before the first retry; defaults to
+	// DefaultRetryDelay.
+	RetryDelay time.Duration
+	// RetryIf decides whether an error is retried. The default retries
+	// connection and server errors.
+	RetryIf func(error) bool
+}
+
+func (s Step) nodeName() string { return s.Name }
+
+func (s Step) validate(names map[string]bool) error {
+	if err := claim(names, "step", s.Name); err != nil {
+		return err
+	}
+	if strings.TrimSpace(s.AgentID) == "" || strings.TrimSpace(s.Entrypoint) == "" {
+		return invalid("step %s needs an agent ID and entrypoint", s.Name)
+	}
+	if s.Retries < 0 {
+		return invalid("step %s has negative retries", s.Name)
+	}
+	return nil
+}
+
+func (s Step) run(ctx context.Context, x *execution, prev interface{}) (interface{}, error) {
+	trace := StepTrace{Name: s.Name, Kind: KindStep, AgentID: s.AgentID, Entrypoint: s.Entrypoint, StartedAt: time.Now()}
+	fail := func(err error) (interface{}, error) {
+		err = nodeError(s.Name, err)
+		trace.finish(nil, err)
+		x.record(trace)
+		return nil, err
+	}
+
+	var values []any
+	if s.Map != nil {
+		kwargs, err := s.Map(x.state(prev))
+		if err != nil {
+			return fail(err)
+		}
+		trace.Input = kwargs
+		values = append(values, runagent.Kws(kwargs))
+	} else {
+		switch t := prev.(type) {
+		case nil:
+		case map[string]interface{}:
+			trace.Input = t
+			values = append(values, runagent.Kws(t))
+		default:
+			trace.Input = []interface{}{t}
+			values = append(values, runagent.Arg(t))
+		}
+	}
+	if s.Retries > 0 {
+		values = append(values, runagent.IdempotencyKey(x.runID+"/"+s.Name))
+	}
+
+	client, err := x.pipeline.client(s)
+	if err != nil {
+		return fail(err)
+	}
+
+	delay := s.RetryDelay
+	if delay <= 0 {
+		delay = DefaultRetryDelay
+	}
+	retryIf := s.RetryIf
+	if retryIf == nil {
+		retryIf = retryable
+	}
+
+	var output interface{}
+	for attempt := 0; ; attempt++ {
+		trace.Attempts = attempt + 1
+		output, trace.RunID, err = s.invoke(ctx, client, values)
+		if err == nil || attempt >= s.Retries || ctx.Err() != nil || !retryIf(err) {
+			break
+		}
+		timer := time.NewTimer(delay << attempt)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fail(ctx.Err())
+		case <-timer.C:
+		}
+	}
+	if err != nil {
+		return fail(err)
+	}
+	trace.finish(output, nil)
+	x.record(trace)
+	x.complete(s.Name, output)
+	return output, nil
+}
+
+func (s Step) invoke(ctx context.Context, client *runagent.RunAgentClient, values []any) (interface{}, string, error) {
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+
+	if !(runagent.EntryPoint{Tag: s.Entrypoint}).Streaming() {
+		result, err := client.RunWithResult(ctx, values...)
+		if err != nil {
+			return nil, "", err
+		}
+		return result.Output, result.RunID, nil
+	}
+
+	stream, err := client.RunStream(ctx, values...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer stream.Close()
+	var chunks []interface{}
+	for {
+		event, more, err := stream.NextEvent(ctx)
+		if err != nil {
+			return nil, stream.RunID(), err
+		}
+		if event != nil && event.Kind == runagent.StreamEventData {
+			chunks = append(chunks, event.Payload)
+		}
+		if !more {
+			return joinChunks(chunks), stream.RunID(), nil
+		}
+	}
+}
+
+// retryable reports whether a failure may succeed on a later attempt.
+func retryable(err error) bool {
+	if runErr, ok := runagent.AsRunAgentError(err); ok {
+		return runErr.Type == runagent.ErrorTypeConnection || runErr.Type == runagent.ErrorTypeServer
+	}
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+func joinChunks(chunks []interface{}) interface{} {
+	var sb strings.Builder
+	for _, chunk := range chunks {
+		str, ok := chunk.(string)
+		if !ok {
+			return chunks
+		}
+		sb.WriteString(str)
+	}
+	return sb.String()
+}
+
+// Sequence runs nodes in order, feeding each the previous output. Its
+// output is the last node's. Name is only required as a Parallel branch.
+type Sequence struct {
+	Name  string
+	Nodes []Node
+}
+
+func (s Sequence) nodeName() string { return s.Name }
+
+func (s Sequence) validate(names map[string]bool) error {
+	if s.Name != "" {
+		if err := claim(names, "sequence", s.Name); err != nil {
+			return err
+		}
+	}
+	if len(s.Nodes) == 0 {
+		return invalid("sequence %s has no nodes", s.Name)
+	}
+	for _, node := range s.Nodes {
+		if err := node.validate(names); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s Sequence) run(ctx context.Context, x *execution, prev interface{}) (interface{}, error) {
+	for _, node := range s.Nodes {
+		if err := ctx.Err(); err != nil {
+			return nil, nodeError(node.nodeName(), err)
+		}
+		out, err := node.run(ctx, x, prev)
+		if err != nil {
+			return nil, err
+		}
+		prev = out
+	}
+	x.complete(s.Name, prev)
+	return prev, nil
+}
+
+// Parallel runs branches concurrently, each receiving the same previous
+// output, and joins their outputs. The first failure cancels the others.
+type Parallel struct {
+	Name     string
+	Branches []Node
+	// Join combines branch outputs keyed by branch name. Nil returns the
+	// map itself.
+	Join func(outputs map[string]interface{}, s *State) (interface{}, error)
+}
+
+func (p Parallel) nodeName() string { return p.Name }
+
+func (p Parallel) validate(names map[string]bool) error {
+	if err := claim(names, "parallel", p.Name); err != nil {
+		return err
+	}
+	if len(p.Branches) == 0 {
+		return invalid("parallel %s has no branches", p.Name)
+	}
+	for _, branch := range p.Branches {
+		if branch.nodeName() == "" {
+			return invalid("parallel %s has a branch without a name", p.Name)
+		}
+		if err := branch.validate(names); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p Parallel) run(ctx context.Context, x *execution, prev interface{}) (interface{}, error) {
+	started := time.Now()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		outputs  = make(map[string]interface{}, len(p.Branches))
+	)
+	for _, branch := range p.Branches {
+		wg.Add(1)
+		go func(branch Node) {
+			defer wg.Done()
+			out, err := branch.run(ctx, x, prev)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				return
+			}
+			outputs[branch.nodeName()] = out
+		}(branch)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	trace := StepTrace{Name: p.Name, Kind: KindParallel, StartedAt: started, Input: prev}
+	var output interface{} = outputs
+	if p.Join != nil {
+		var err error
+		if output, err = p.Join(outputs, x.state(prev)); err != nil {
+			err = nodeError(p.Name, err)
+			trace.finish(nil, err)
+			x.record(trace)
+			return nil, err
+		}
+	}
+	trace.finish(output, nil)
+	x.record(trace)
+	x.complete(p.Name, output)
+	return output, nil
+}
+
+// Route runs the node selected by Select. An unknown key runs Default; with
+// no Default, an empty key passes the previous output through and any other
+// key fails with ROUTE_NOT_FOUND.
+type Route struct {
+	Name    string
+	Select  func(s *State) (string, error)
+	Routes  map[string]Node
+	Default Node
+}
+
+func (r Route) nodeName() string { return r.Name }
+
+func (r Route) validate(names map[string]bool) error {
+	if err := claim(names, "route", r.Name); err != nil {
+		return err
+	}
+	if r.Select == nil {
+		return invalid("route %s has no Select function", r.Name)
+	}
+	if len(r.Routes) == 0 && r.Default == nil {
+		return invalid("route %s has no routes", r.Name)
+	}
+	for _, node := range r.Routes {
+		if err := node.validate(names); err != nil {
+			return err
+		}
+	}
+	if r.Default != nil {
+		return r.Default.validate(names)
+	}
+	return nil
+}
+
+func (r Route) run(ctx context.Context, x *execution, prev interface{}) (interface{}, error) {
+	trace := StepTrace{Name: r.Name, Kind: KindRoute, StartedAt: time.Now(), Input: prev}
+	key, err := r.Select(x.state(prev))
+	if err != nil {
+		err = nodeError(r.Name, err)
+		trace.finish(nil, err)
+		x.record(trace)
+		return nil, err
+	}
+	trace.Route = key
+
+	node, ok := r.Routes[key]
+	if !ok {
+		node = r.Default
+	}
+	if node == nil {
+		if key != "" {
+			err := nodeError(r.Name, &runagent.RunAgentError{
+				Type:    runagent.ErrorTypeValidation,
+				Code:    "ROUTE_NOT_FOUND",
+				Message: fmt.Sprintf("no route for %q", key),
+			})
+			trace.finish(nil, err)
+			x.record(trace)
+			return nil, err
+		}
+		trace.finish(prev, nil)
+		x.record(trace)
+		x.complete(r.Name, prev)
+		return prev, nil
+	}
+	trace.finish(nil, nil)
+	x.record(trace)
+
+	out, err := node.run(ctx, x, prev)
+	if err != nil {
+		return nil, err
+	}
+	x.complete(r.Name, out)
+	return out, nil
+}
+
+func claim(names map[string]bool, kind, name string) error {
+	if strings.TrimSpace(name) == "" {
+		return invalid("%s without a name", kind)
+	}
+	if names[name] {
+		return invalid("duplicate node name %q", name)
+	}
+	names[name] = true
+	return nil
+}
@@ -0,0 +1,207 @@
+// Package pipeline chains RunAgent entrypoints declaratively. A pipeline is
+// a sequence of nodes:
+//
+//   - Step runs one agent entrypoint, with an optional mapping from earlier
+//     outputs to its kwargs and per-step retries.
+//   - Sequence groups nodes, e.g. as one branch of a Parallel.
+//   - Parallel runs branches concurrently and joins their outputs.
+//   - Route picks one node to run from the state.
+//
+// Each node receives the previous node's output. Every run returns a trace of
+// each step's input, output, attempts and timing.
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/runagent-dev/runagent-go"
+	"github.com/runagent-dev/runagent-go/internal/utils"
+)
+
+// Options control New.
+type Options struct {
+	// Name labels the pipeline in results.
+	Name string
+	// Config is the base client configuration. AgentID and EntrypointTag are
+	// set per step; Step.Config overrides it for one step.
+	Config runagent.Config
+	// OnStep is called as each trace entry is recorded. Calls within a run
+	// are serialized.
+	OnStep func(StepTrace)
+}
+
+// Pipeline runs its nodes in order. It is safe for concurrent use, and
+// reuses one client per agent and entrypoint across runs.
+type Pipeline struct {
+	opts  Options
+	nodes []Node
+
+	mu      sync.Mutex
+	clients map[clientKey]*runagent.RunAgentClient
+}
+
+type clientKey struct {
+	agentID, tag string
+	cfg          *runagent.Config
+}
+
+// New validates the nodes and returns a pipeline. Node names must be
+// non-empty, except for Sequences outside a Parallel, and unique.
+func New(opts Options, nodes ...Node) (*Pipeline, error) {
+	if len(nodes) == 0 {
+		return nil, invalid("pipeline has no nodes")
+	}
+	names := map[string]bool{}
+	for _, node := range nodes {
+		if err := node.validate(names); err != nil {
+			return nil, err
+		}
+	}
+	return &Pipeline{opts: opts, nodes: nodes, clients: map[clientKey]*runagent.RunAgentClient{}}, nil
+}
+
+// Result is the outcome of one pipeline run.
+type Result struct {
+	Pipeline string `json:"pipeline,omitempty"`
+	// RunID identifies this pipeline run; steps with retries derive their
+	// idempotency keys from it.
+	RunID     string        `json:"run_id"`
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration"`
+	// Output is the last node's output.
+	Output interface{} `json:"output,omitempty"`
+	// Outputs holds every completed node's output by name.
+	Outputs map[string]interface{} `json:"outputs"`
+	// Trace lists steps, joins and routing decisions in completion order.
+	Trace []StepTrace `json:"trace"`
+}
+
+// Run executes the pipeline. The first node receives input as its previous
+// output. On failure the partial result is returned with the error, which
+// names the failed node in its message and in Details["step"].
+func (p *Pipeline) Run(ctx context.Context, input interface{}) (*Result, error) {
+	x := &execution{
+		pipeline: p,
+		runID:    utils.NewUUID(),
+		input:    input,
+		outputs:  map[string]interface{}{},
+	}
+	started := time.Now()
+	output, err := Sequence{Nodes: p.nodes}.run(ctx, x, input)
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	result := &Result{
+		Pipeline:  p.opts.Name,
+		RunID:     x.runID,
+		StartedAt: started,
+		Duration:  time.Since(started),
+		Outputs:   x.outputs,
+		Trace:     x.trace,
+	}
+	if err != nil {
+		return result, err
+	}
+	result.Output = output
+	return result, nil
+}
+
+func (p *Pipeline) client(s Step) (*runagent.RunAgentClient, error) {
+	key := clientKey{agentID: s.AgentID, tag: s.Entrypoint, cfg: s.Config}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if client, ok := p.clients[key]; ok {
+		return client, nil
+	}
+	cfg := p.opts.Config
+	if s.Config != nil {
+		cfg = *s.Config
+	}
+	cfg.AgentID, cfg.EntrypointTag = s.AgentID, s.Entrypoint
+	client, err := runagent.NewRunAgentClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	p.clients[key] = client
+	return client, nil
+}
+
+// State is what mapping, routing and join functions see.
+type State struct {
+	// Input is the value passed to Pipeline.Run.
+	Input interface{}
+	// Previous is the output of the node that ran just before this one, or
+	// Input for the first node.
+	Previous interface{}
+
+	x *execution
+}
+
+// Output returns the output of a completed node by name.
+func (s *State) Output(name string) (interface{}, bool) {
+	s.x.mu.Lock()
+	defer s.x.mu.Unlock()
+	out, ok := s.x.outputs[name]
+	return out, ok
+}
+
+// execution is the shared state of one pipeline run.
+type execution struct {
+	pipeline *Pipeline
+	runID    string
+	input    interface{}
+
+	mu      sync.Mutex
+	outputs map[string]interface{}
+	trace   []StepTrace
+}
+
+func (x *execution) state(prev interface{}) *State {
+	return &State{Input: x.input, Previous: prev, x: x}
+}
+
+func (x *execution) complete(name string, output interface{}) {
+	if name == "" {
+		return
+	}
+	x.mu.Lock()
+	x.outputs[name] = output
+	x.mu.Unlock()
+}
+
+func (x *execution) record(t StepTrace) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.trace = append(x.trace, t)
+	if x.pipeline.opts.OnStep != nil {
+		x.pipeline.opts.OnStep(t)
+	}
+}
+
+func invalid(format string, args ...interface{}) error {
+	return &runagent.RunAgentError{
+		Type:    runagent.ErrorTypeValidation,
+		Code:    "INVALID_PIPELINE",
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// nodeError attributes err to the named node, keeping its type and code so
+// callers can still branch on them.
+func nodeError(name string, err error) error {
+	wrapped := runagent.RunAgentError{Type: runagent.ErrorTypeUnknown, Message: err.Error()}
+	if runErr, ok := runagent.AsRunAgentError(err); ok {
+		wrapped = *runErr
+	}
+	details := map[string]interface{}{"step": name}
+	for k, v := range wrapped.Details {
+		details[k] = v
+	}
+	wrapped.Details = details
+	wrapped.Message = fmt.Sprintf("step %s: %s", name, wrapped.Message)
+	wrapped.Cause = err
+	return &wrapped
+}
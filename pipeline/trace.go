@@ -0,0 +1,53 @@
+package pipeline
+
+import (
+	"time"
+
+	"github.com/runagent-dev/runagent-go"
+)
+
+// TraceKind distinguishes trace entries.
+type TraceKind string
+
+const (
+	KindStep     TraceKind = "step"
+	KindParallel TraceKind = "parallel"
+	KindRoute    TraceKind = "route"
+)
+
+// StepTrace records one step run, parallel join or routing decision.
+type StepTrace struct {
+	Name       string    `json:"name"`
+	Kind       TraceKind `json:"kind"`
+	AgentID    string    `json:"agent_id,omitempty"`
+	Entrypoint string    `json:"entrypoint,omitempty"`
+	// Input is the kwargs map or positional arguments sent to a step, or
+	// the previous output for joins and routes.
+	Input interface{} `json:"input,omitempty"`
+	// Output is the step output, the joined output, or for routes the
+	// previous output when no route ran.
+	Output interface{} `json:"output,omitempty"`
+	// Route is the key chosen by a Route.
+	Route string `json:"route,omitempty"`
+	// Attempts counts step runs, including retries.
+	Attempts  int           `json:"attempts,omitempty"`
+	RunID     string        `json:"run_id,omitempty"`
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration"`
+	Error     string        `json:"error,omitempty"`
+	ErrorType string        `json:"error_type,omitempty"`
+	ErrorCode string        `json:"error_code,omitempty"`
+}
+
+func (t *StepTrace) finish(output interface{}, err error) {
+	t.Duration = time.Since(t.StartedAt)
+	t.Output = output
+	if err == nil {
+		return
+	}
+	t.Error = err.Error()
+	if runErr, ok := runagent.AsRunAgentError(err); ok {
+		t.ErrorType = string(runErr.Type)
+		t.ErrorCode = runErr.Code
+	}
+}
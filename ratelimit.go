@@ -0,0 +1,200 @@
+package runagent
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit configures client-side throttling for one agent entrypoint.
+// Limiters are shared by every RunAgentClient in the process that targets
+// the same agent ID and entrypoint tag.
+type RateLimit struct {
+	// RequestsPerSecond refills the token bucket. Zero disables rate limiting.
+	RequestsPerSecond float64
+	// Burst is the bucket capacity; defaults to 1.
+	Burst int
+	// MaxInFlight bounds concurrent requests. Zero means unbounded.
+	MaxInFlight int
+}
+
+var sharedRateLimiters = struct {
+	mu sync.Mutex
+	m  map[string]*rateLimiter
+}{m: map[string]*rateLimiter{}}
+
+// rateLimiter combines a token bucket, an in-flight semaphore and a
+// server-imposed pause derived from Retry-After / X-RateLimit-* headers.
+type rateLimiter struct {
+	mu           sync.Mutex
+	rate         float64
+	burst        float64
+	tokens       float64
+	last         time.Time
+	blockedUntil time.Time
+	sem          chan struct{}
+}
+
+// sharedRateLimiter returns the process-wide limiter for the key, applying
+// the latest configuration.
+func sharedRateLimiter(agentID, entrypointTag string, cfg *RateLimit) *rateLimiter {
+	if cfg == nil {
+		return nil
+	}
+
+	key := agentID + "/" + entrypointTag
+	sharedRateLimiters.mu.Lock()
+	defer sharedRateLimiters.mu.Unlock()
+
+	limiter, ok := sharedRateLimiters.m[key]
+	if !ok {
+		limiter = &rateLimiter{last: time.Now()}
+		sharedRateLimiters.m[key] = limiter
+	}
+	limiter.configure(*cfg)
+	return limiter
+}
+
+func (l *rateLimiter) configure(cfg RateLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	burst := float64(cfg.Burst)
+	if burst <= 0 {
+		burst = 1
+	}
+	if l.rate == 0 && l.burst == 0 {
+		l.tokens = burst
+	}
+	l.rate = cfg.RequestsPerSecond
+	l.burst = burst
+	l.tokens = math.Min(l.tokens, burst)
+
+	if cfg.MaxInFlight > 0 && (l.sem == nil || cap(l.sem) != cfg.MaxInFlight) {
+		l.sem = make(chan struct{}, cfg.MaxInFlight)
+	} else if cfg.MaxInFlight <= 0 {
+		l.sem = nil
+	}
+}
+
+// acquire blocks until a request may be sent. The returned release func
+// must be called once the request finishes.
+func (l *rateLimiter) acquire(ctx context.Context) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	l.mu.Lock()
+	sem := l.sem
+	l.mu.Unlock()
+
+	release := func() {}
+	if sem != nil {
+		select {
+		case sem <- struct{}{}:
+			var once sync.Once
+			release = func() { once.Do(func() { <-sem }) }
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	for {
+		wait := l.reserve()
+		if wait <= 0 {
+			return release, nil
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			release()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// reserve takes a token when available and otherwise reports how long to wait.
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(l.blockedUntil) {
+		return l.blockedUntil.Sub(now)
+	}
+	if l.rate <= 0 {
+		return 0
+	}
+
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+// observe adapts to the server's rate-limit headers.
+func (l *rateLimiter) observe(resp *http.Response) {
+	if l == nil || resp == nil {
+		return
+	}
+
+	pause := retryAfter(resp.Header)
+	if pause <= 0 && strings.TrimSpace(resp.Header.Get("X-RateLimit-Remaining")) == "0" {
+		pause = rateLimitReset(resp.Header)
+	}
+	if pause <= 0 && resp.StatusCode == http.StatusTooManyRequests {
+		pause = time.Second
+	}
+	if pause <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := time.Now().Add(pause); until.After(l.blockedUntil) {
+		l.blockedUntil = until
+	}
+	l.tokens = 0
+}
+
+// retryAfter parses Retry-After as delay-seconds or an HTTP date.
+func retryAfter(header http.Header) time.Duration {
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Duration(secs * float64(time.Second))
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
+
+// rateLimitReset parses X-RateLimit-Reset as either a Unix timestamp or a
+// number of seconds until the window resets.
+func rateLimitReset(header http.Header) time.Duration {
+	value := strings.TrimSpace(header.Get("X-RateLimit-Reset"))
+	if value == "" {
+		return 0
+	}
+	secs, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+	// Values this large can only be absolute epoch seconds.
+	if secs > 1e9 {
+		return time.Until(time.Unix(int64(secs), 0))
+	}
+	return time.Duration(secs * float64(time.Second))
+}
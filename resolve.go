@@ -0,0 +1,177 @@
+package runagent
+
+import (
+	"strings"
+
+	"github.com/runagent-dev/runagent-go/internal/config"
+	"github.com/runagent-dev/runagent-go/internal/constants"
+	"github.com/runagent-dev/runagent-go/internal/db"
+)
+
+// resolvedConfig is the effective configuration a client is built from,
+// with the source of each value. NewRunAgentClient and Diagnose both go
+// through resolveConfig so they cannot disagree on precedence: explicit
+// Config fields, then environment variables, then the profile, then the
+// local registry and defaults.
+type resolvedConfig struct {
+	profileName   string
+	profileSource ConfigSource
+	profile       *config.Profile
+
+	local         bool
+	localSource   ConfigSource
+	timeout       int
+	timeoutSource ConfigSource
+
+	// Remote settings. apiKey is empty when Config.Credentials supplies
+	// the key instead.
+	baseURL       string
+	baseURLSource ConfigSource
+	credentials   CredentialProvider
+	apiKey        string
+	apiKeySource  ConfigSource
+
+	// Local settings.
+	socketPath       string
+	socketPathSource ConfigSource
+	host             string
+	hostSource       ConfigSource
+	port             int
+	portSource       ConfigSource
+	agentConfig      *AgentConfig
+
+	framework       Framework
+	frameworkSource ConfigSource
+	transport       string
+	transportSource ConfigSource
+	tls             *TLSConfig
+	tlsSource       ConfigSource
+}
+
+// resolveConfig applies the environment and profile to cfg. The registry is
+// not consulted; see needsRegistry and applyRegistry.
+func resolveConfig(cfg Config) (*resolvedConfig, error) {
+	env := loadEnvConfig()
+	r := &resolvedConfig{}
+
+	r.profileName, r.profileSource = resolveString(cfg.Profile, env.profile, "")
+	profile, err := loadProfile(r.profileName)
+	if err != nil {
+		return nil, err
+	}
+	r.profile = profile
+
+	switch {
+	case cfg.Local != nil:
+		r.local, r.localSource = *cfg.Local, SourceExplicit
+	case env.local != nil:
+		r.local, r.localSource = *env.local, SourceEnv
+	case profile.Local != nil:
+		r.local, r.localSource = *profile.Local, SourceProfile
+	default:
+		r.localSource = SourceDefault
+	}
+
+	r.timeout, r.timeoutSource = resolveInt(cfg.TimeoutSeconds, env.timeoutSeconds, profile.TimeoutSeconds)
+	if r.timeout <= 0 {
+		r.timeout, r.timeoutSource = constants.DefaultTimeoutSeconds, SourceDefault
+	}
+
+	r.baseURL, r.baseURLSource = resolveString(cfg.BaseURL, env.baseURL, profile.BaseURL)
+	if r.baseURL == "" {
+		r.baseURL = constants.DefaultBaseURL
+	}
+
+	// An explicit APIKey wins over Credentials; without either, the
+	// env/profile key is used as a static credential.
+	if strings.TrimSpace(cfg.APIKey) != "" || cfg.Credentials == nil {
+		r.apiKey, r.apiKeySource = resolveString(cfg.APIKey, env.apiKey, profile.APIKey)
+		r.credentials = StaticCredentials(r.apiKey)
+	} else {
+		r.credentials, r.apiKeySource = cfg.Credentials, SourceExplicit
+	}
+
+	// An explicit host or port outranks the environment's socket.
+	if cfg.SocketPath != "" || cfg.Host != "" || cfg.Port != 0 {
+		r.socketPath, r.socketPathSource = resolveString(cfg.SocketPath, "", "")
+	} else {
+		r.socketPath, r.socketPathSource = resolveString("", env.socketPath, "")
+	}
+	r.host, r.hostSource = resolveString(cfg.Host, env.host, "")
+	r.port, r.portSource = resolveInt(cfg.Port, env.port, 0)
+
+	r.framework, r.frameworkSource = cfg.Framework, SourceDefault
+	if cfg.Framework != "" {
+		r.frameworkSource = SourceExplicit
+	}
+
+	r.transport, r.transportSource = resolveString(string(cfg.StreamTransport), env.transport, "")
+
+	switch {
+	case cfg.TLS != nil:
+		r.tls, r.tlsSource = cfg.TLS, SourceExplicit
+	case env.caFile != "" || env.clientCert != "":
+		r.tls = &TLSConfig{CAFile: env.caFile, CertFile: env.clientCert, KeyFile: env.clientKey}
+		r.tlsSource = SourceEnv
+	}
+	return r, nil
+}
+
+// needsRegistry reports whether a local agent's address must come from the
+// registry because no socket or complete host/port was given.
+func (r *resolvedConfig) needsRegistry() bool {
+	return r.local && r.socketPath == "" && (r.host == "" || r.port == 0)
+}
+
+// applyRegistry fills the unset local settings from the agent's registry
+// row. A registered socket takes precedence over its host/port.
+func (r *resolvedConfig) applyRegistry(agent *db.Agent) {
+	if r.host == "" && r.port == 0 && agent.SocketPath != "" {
+		r.socketPath, r.socketPathSource = agent.SocketPath, SourceRegistry
+	}
+	if r.host == "" {
+		r.host, r.hostSource = agent.Host, SourceRegistry
+	}
+	if r.port == 0 {
+		r.port, r.portSource = agent.Port, SourceRegistry
+	}
+	if agent.AgentPath != "" {
+		// Best effort: the config only adds offline tag checks and
+		// architecture, so a missing or stale file is not an error.
+		r.agentConfig, _ = LoadAgentConfig(agent.AgentPath)
+	}
+	if r.framework == "" && agent.Framework != "" {
+		r.framework, r.frameworkSource = Framework(agent.Framework), SourceRegistry
+	}
+	if r.framework == "" && r.agentConfig != nil && r.agentConfig.Framework != "" {
+		r.framework, r.frameworkSource = Framework(r.agentConfig.Framework), SourceRegistry
+	}
+}
+
+// resolveString returns the first non-blank value and where it came from.
+func resolveString(explicit, env, profile string) (string, ConfigSource) {
+	switch {
+	case strings.TrimSpace(explicit) != "":
+		return strings.TrimSpace(explicit), SourceExplicit
+	case strings.TrimSpace(env) != "":
+		return strings.TrimSpace(env), SourceEnv
+	case strings.TrimSpace(profile) != "":
+		return strings.TrimSpace(profile), SourceProfile
+	default:
+		return "", SourceDefault
+	}
+}
+
+// resolveInt returns the first positive value and where it came from.
+func resolveInt(explicit, env, profile int) (int, ConfigSource) {
+	switch {
+	case explicit > 0:
+		return explicit, SourceExplicit
+	case env > 0:
+		return env, SourceEnv
+	case profile > 0:
+		return profile, SourceProfile
+	default:
+		return 0, SourceDefault
+	}
+}
@@ -0,0 +1,219 @@
+package runagent
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	headerRequestID      = "X-Request-ID"
+	headerRunID          = "X-Run-ID"
+	headerIdempotencyKey = "Idempotency-Key"
+)
+
+// RunResult is the output of a run together with the response metadata
+// that Run discards: identifiers, timing and usage.
+type RunResult struct {
+	// Output is the decoded payload, identical to what Run returns.
+	Output interface{}
+	// Envelope is the raw response body.
+	Envelope   json.RawMessage
+	HTTPStatus int
+	Header     http.Header
+	// AgentID is the agent that served the run, as reported by the server.
+	AgentID string
+	// ExecutionTime is the server-reported execution time, when available.
+	ExecutionTime time.Duration
+	// Latency is the client-measured time from sending the request to
+	// reading the full response.
+	Latency time.Duration
+	// Usage holds token and cost accounting when the framework reports it.
+	Usage *Usage
+	// Cached is true when the result was served from Config.Cache.
+	Cached bool
+
+	// RequestID is generated by the client for every request, or replaced by
+	// the server's value when it assigns its own.
+	RequestID string
+	// RunID is the server's execution identifier, when reported.
+	RunID string
+	// IdempotencyKey echoes the caller-supplied key, if any.
+	IdempotencyKey string
+}
+
+// Usage is token and cost accounting reported by the agent framework.
+// Counts are normalized from OpenAI (prompt/completion), Anthropic and
+// LangChain (input/output) naming.
+type Usage struct {
+	InputTokens  int64
+	OutputTokens int64
+	TotalTokens  int64
+	// Cost is the reported cost, in the provider's currency, when present.
+	Cost float64
+	// Raw is the usage object as reported.
+	Raw map[string]interface{}
+}
+
+// usageKeys are the envelope keys known to carry usage data.
+var usageKeys = []string{"usage", "token_usage", "usage_metadata"}
+
+// usageContainers are searched for usage data before other nested objects,
+// in this order: the envelope payload, then LangChain message and LLMResult
+// metadata.
+var usageContainers = []string{"data", "output_data", "response_metadata", "llm_output", "metadata"}
+
+func setRequestIDHeaders(header http.Header, requestID, idempotencyKey string) {
+	if requestID != "" {
+		header.Set(headerRequestID, requestID)
+	}
+	if idempotencyKey != "" {
+		header.Set(headerIdempotencyKey, idempotencyKey)
+	}
+}
+
+// captureResponse records the response metadata for a completed request.
+func (r *RunResult) captureResponse(resp *http.Response, body []byte, latency time.Duration) {
+	r.HTTPStatus = resp.StatusCode
+	r.Header = resp.Header.Clone()
+	r.Envelope = append(json.RawMessage(nil), body...)
+	r.Latency = latency
+	r.captureServerIDs(resp.Header, body)
+
+	var envelope map[string]interface{}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return
+	}
+	data, _ := envelope["data"].(map[string]interface{})
+
+	r.AgentID = firstNonEmpty(stringField(envelope, "agent_id"), stringField(data, "agent_id"))
+	for _, m := range []map[string]interface{}{envelope, data} {
+		if secs, ok := m["execution_time"].(float64); ok {
+			r.ExecutionTime = time.Duration(secs * float64(time.Second))
+			break
+		}
+	}
+	r.Usage = findUsage(envelope, 4)
+}
+
+// captureCached fills the metadata from a cached response. Latency stays
+// zero because no request was sent.
+func (r *RunResult) captureCached(entry *cachedResponse) {
+	r.captureResponse(&http.Response{StatusCode: entry.Status, Header: entry.Header}, entry.Body, 0)
+}
+
+// captureServerIDs records the request and run IDs reported by the server,
+// from headers first and then from the response envelope.
+func (r *RunResult) captureServerIDs(header http.Header, body []byte) {
+	if id := strings.TrimSpace(header.Get(headerRequestID)); id != "" {
+		r.RequestID = id
+	}
+	if id := strings.TrimSpace(header.Get(headerRunID)); id != "" {
+		r.RunID = id
+		return
+	}
+
+	var envelope struct {
+		RunID       string          `json:"run_id"`
+		ExecutionID string          `json:"execution_id"`
+		Data        json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return
+	}
+	// data may be any JSON value; only objects can carry IDs.
+	var data struct {
+		RunID       string `json:"run_id"`
+		ExecutionID string `json:"execution_id"`
+	}
+	json.Unmarshal(envelope.Data, &data)
+	r.RunID = firstNonEmpty(envelope.RunID, envelope.ExecutionID, data.RunID, data.ExecutionID)
+}
+
+// attachRequestIDs stamps SDK errors with the identifiers of the request that
+// produced them. Other errors, such as context cancellation, pass through.
+func attachRequestIDs(err error, requestID, runID string) error {
+	runErr, ok := AsRunAgentError(err)
+	if !ok {
+		return err
+	}
+	if runErr.RequestID == "" {
+		runErr.RequestID = requestID
+	}
+	if runErr.RunID == "" {
+		runErr.RunID = runID
+	}
+	return err
+}
+
+// findUsage searches the envelope up to depth levels deep for an object that
+// looks like usage data, checking the known keys at each level before
+// descending. Known containers are searched first and other keys in sorted
+// order, so the result does not depend on map iteration order.
+func findUsage(m map[string]interface{}, depth int) *Usage {
+	if m == nil || depth <= 0 {
+		return nil
+	}
+	for _, key := range usageKeys {
+		if raw, ok := m[key].(map[string]interface{}); ok {
+			if usage := parseUsage(raw); usage != nil {
+				return usage
+			}
+		}
+	}
+	keys := append([]string(nil), usageContainers...)
+	var rest []string
+	for key := range m {
+		if !slices.Contains(usageContainers, key) {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+	for _, key := range append(keys, rest...) {
+		if child, ok := m[key].(map[string]interface{}); ok {
+			if usage := findUsage(child, depth-1); usage != nil {
+				return usage
+			}
+		}
+	}
+	return nil
+}
+
+func parseUsage(raw map[string]interface{}) *Usage {
+	usage := &Usage{
+		InputTokens:  intField(raw, "input_tokens", "prompt_tokens"),
+		OutputTokens: intField(raw, "output_tokens", "completion_tokens"),
+		TotalTokens:  intField(raw, "total_tokens"),
+		Raw:          raw,
+	}
+	for _, key := range []string{"cost", "total_cost", "cost_usd"} {
+		if cost, ok := raw[key].(float64); ok {
+			usage.Cost = cost
+			break
+		}
+	}
+	if usage.TotalTokens == 0 {
+		usage.TotalTokens = usage.InputTokens + usage.OutputTokens
+	}
+	if usage.TotalTokens == 0 && usage.Cost == 0 {
+		return nil
+	}
+	return usage
+}
+
+func intField(m map[string]interface{}, keys ...string) int64 {
+	for _, key := range keys {
+		if v, ok := m[key].(float64); ok {
+			return int64(v)
+		}
+	}
+	return 0
+}
+
+func stringField(m map[string]interface{}, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
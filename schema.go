@@ -0,0 +1,137 @@
+package runagent
+
+import (
+	"sort"
+	"strings"
+)
+
+// EntryPointParameter is one declared entrypoint parameter. Type accepts
+// JSON Schema names (string, integer, ...) or Python annotations (str,
+// int, List[str], ...).
+type EntryPointParameter struct {
+	Name        string      `json:"name"`
+	Type        string      `json:"type,omitempty"`
+	Required    bool        `json:"required,omitempty"`
+	Default     interface{} `json:"default,omitempty"`
+	Description string      `json:"description,omitempty"`
+}
+
+// extractorSchemaKeys are the Extractor entries that may carry a schema.
+var extractorSchemaKeys = []string{"input_schema", "schema", "parameters"}
+
+// JSONSchema returns the entrypoint's input schema as a JSON Schema object,
+// or nil when none is declared. It prefers InputSchema, then Parameters,
+// then schema metadata found in Extractor.
+func (e EntryPoint) JSONSchema() map[string]interface{} {
+	if len(e.InputSchema) > 0 {
+		return e.InputSchema
+	}
+	if len(e.Parameters) > 0 {
+		return parametersSchema(e.Parameters)
+	}
+	for _, key := range extractorSchemaKeys {
+		switch t := e.Extractor[key].(type) {
+		case map[string]interface{}:
+			if len(t) > 0 {
+				return t
+			}
+		case []interface{}:
+			if params := decodeParameters(t); len(params) > 0 {
+				return parametersSchema(params)
+			}
+		}
+	}
+	return nil
+}
+
+// parametersSchema converts declared parameters to an object schema.
+func parametersSchema(params []EntryPointParameter) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+	for _, p := range params {
+		if p.Name == "" {
+			continue
+		}
+		prop := jsonSchemaForType(p.Type)
+		if p.Description != "" {
+			prop["description"] = p.Description
+		}
+		if p.Default != nil {
+			prop["default"] = p.Default
+		}
+		properties[p.Name] = prop
+		if p.Required {
+			required = append(required, p.Name)
+		}
+	}
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		sort.Strings(required)
+		list := make([]interface{}, len(required))
+		for i, name := range required {
+			list[i] = name
+		}
+		schema["required"] = list
+	}
+	return schema
+}
+
+func decodeParameters(raw []interface{}) []EntryPointParameter {
+	var params []EntryPointParameter
+	for _, item := range raw {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		p := EntryPointParameter{
+			Name:        stringField(m, "name"),
+			Type:        stringField(m, "type"),
+			Description: stringField(m, "description"),
+			Default:     m["default"],
+		}
+		if required, ok := m["required"].(bool); ok {
+			p.Required = required
+		}
+		if p.Name != "" {
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+// jsonSchemaForType maps a JSON Schema or Python type name to a schema.
+func jsonSchemaForType(name string) map[string]interface{} {
+	name = strings.TrimSpace(name)
+	lower := strings.ToLower(name)
+	if strings.HasPrefix(lower, "optional[") && strings.HasSuffix(lower, "]") {
+		return jsonSchemaForType(name[len("optional[") : len(name)-1])
+	}
+	if open := strings.Index(lower, "["); open > 0 && strings.HasSuffix(lower, "]") {
+		outer, inner := lower[:open], name[open+1:len(name)-1]
+		switch outer {
+		case "list", "sequence", "tuple", "set":
+			return map[string]interface{}{"type": "array", "items": jsonSchemaForType(inner)}
+		case "dict", "mapping":
+			return map[string]interface{}{"type": "object"}
+		}
+	}
+	switch lower {
+	case "str", "string":
+		return map[string]interface{}{"type": "string"}
+	case "int", "integer":
+		return map[string]interface{}{"type": "integer"}
+	case "float", "number":
+		return map[string]interface{}{"type": "number"}
+	case "bool", "boolean":
+		return map[string]interface{}{"type": "boolean"}
+	case "list", "array", "tuple", "set":
+		return map[string]interface{}{"type": "array"}
+	case "dict", "object", "mapping":
+		return map[string]interface{}{"type": "object"}
+	default:
+		return map[string]interface{}{}
+	}
+}
@@ -0,0 +1,337 @@
+package runagent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/runagent-dev/runagent-go/internal/constants"
+	"github.com/runagent-dev/runagent-go/internal/utils"
+)
+
+// SessionOptions customize an interactive streaming session.
+type SessionOptions struct {
+	// InputTimeout bounds how long the session waits for a response after the
+	// agent asks for input. Zero disables the timeout.
+	InputTimeout time.Duration
+}
+
+// InputRequest describes an agent pause that needs a human response.
+type InputRequest struct {
+	ID         string
+	Prompt     string
+	Payload    interface{}
+	ReceivedAt time.Time
+}
+
+// StreamSession is a bidirectional stream that lets callers answer agents
+// which pause mid-run for approval or extra input.
+type StreamSession struct {
+	iter *StreamIterator
+	conn *websocket.Conn
+	opts SessionOptions
+
+	mu       sync.Mutex
+	pending  *InputRequest
+	timer    *time.Timer
+	timedOut bool
+}
+
+type sessionFrame struct {
+	Type        string      `json:"type"`
+	InterruptID string      `json:"interrupt_id,omitempty"`
+	Data        interface{} `json:"data,omitempty"`
+}
+
+// RunSession starts an interactive streaming execution via WebSocket.
+// Use NextEvent to read frames and Send, Resume or Approve to answer
+// StreamEventInputRequired events.
+func (c *RunAgentClient) RunSession(ctx context.Context, opts SessionOptions, values ...any) (*StreamSession, error) {
+	if !isStreamTag(c.entrypointTag) {
+		return nil, newError(
+			ErrorTypeValidation,
+			"non-stream entrypoint must be invoked with Run",
+			withCode("NON_STREAM_ENTRYPOINT"),
+			withSuggestion("Use client.Run(...) for non-stream tags"),
+		)
+	}
+
+	input, err := c.prepareInput(ctx, values...)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.resolveAttachments(ctx, &input); err != nil {
+		return nil, err
+	}
+
+	payload := input.toAPIPayload(c.entrypointTag, constants.DefaultStreamTimeout, false)
+	payload.AsyncExecution = false
+	payload.Interactive = true
+	payload.RequestID = utils.NewUUID()
+	payload.IdempotencyKey = input.IdempotencyKey
+
+	release, err := c.rateLimiter.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.breaker.allow(); err != nil {
+		release()
+		return nil, err
+	}
+	// Sessions need a bidirectional channel, so they always use WebSocket.
+	conn, err := c.dialWebSocket(ctx, payload)
+	c.breaker.record(err)
+	if err != nil {
+		release()
+		return nil, attachRequestIDs(err, payload.RequestID, "")
+	}
+
+	iter := newStreamIterator(wsFrameReader{conn: conn})
+	iter.onClose = release
+	iter.requestID = payload.RequestID
+	return &StreamSession{
+		iter: iter,
+		conn: conn,
+		opts: opts,
+	}, nil
+}
+
+// NextEvent blocks until the next frame is available. When the agent asks for
+// input, the returned event has kind StreamEventInputRequired and the session
+// waits at most SessionOptions.InputTimeout for a response.
+func (s *StreamSession) NextEvent(ctx context.Context) (*StreamEvent, bool, error) {
+	if err := s.timeoutError(); err != nil {
+		return nil, false, err
+	}
+
+	event, more, err := s.iter.NextEvent(ctx)
+	if err != nil {
+		if timeoutErr := s.timeoutError(); timeoutErr != nil {
+			return nil, false, timeoutErr
+		}
+		return event, more, err
+	}
+
+	if event != nil && event.Kind == StreamEventInputRequired {
+		s.awaitInput(event.Input)
+	}
+	if !more {
+		s.clearPending()
+	}
+	return event, more, nil
+}
+
+// Pending returns the outstanding input request, if any.
+func (s *StreamSession) Pending() *InputRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pending
+}
+
+// RequestID returns the client-generated request ID sent with the session.
+func (s *StreamSession) RequestID() string { return s.iter.RequestID() }
+
+// RunID returns the server's run ID once it has been observed.
+func (s *StreamSession) RunID() string { return s.iter.RunID() }
+
+// Send delivers a follow-up message to the agent.
+func (s *StreamSession) Send(ctx context.Context, message interface{}) error {
+	return s.write(ctx, sessionFrame{Type: "message", Data: message})
+}
+
+// Resume answers the pending interrupt with the given value, mirroring
+// LangGraph's Command(resume=...).
+func (s *StreamSession) Resume(ctx context.Context, value interface{}) error {
+	return s.write(ctx, sessionFrame{Type: "resume", Data: value})
+}
+
+// Approve answers the pending interrupt with an approval decision.
+func (s *StreamSession) Approve(ctx context.Context, approved bool, comment string) error {
+	data := map[string]interface{}{"approved": approved}
+	if comment != "" {
+		data["comment"] = comment
+	}
+	return s.write(ctx, sessionFrame{Type: "approval", Data: data})
+}
+
+// Close terminates the session and the underlying WebSocket connection.
+func (s *StreamSession) Close() error {
+	s.clearPending()
+	return s.iter.Close()
+}
+
+func (s *StreamSession) write(ctx context.Context, frame sessionFrame) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.timedOut {
+		return newInputTimeoutError(s.pending)
+	}
+	if s.iter.closed.Load() {
+		return newError(ErrorTypeConnection, "stream session is closed")
+	}
+	if s.pending != nil {
+		frame.InterruptID = s.pending.ID
+	}
+
+	data, err := json.Marshal(frame)
+	if err != nil {
+		return newError(ErrorTypeValidation, "failed to serialize session message", withCause(err))
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		s.conn.SetWriteDeadline(deadline)
+		defer s.conn.SetWriteDeadline(time.Time{})
+	}
+	if err := s.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		return newError(ErrorTypeConnection, "failed to send session message", withCause(err))
+	}
+
+	// Any reply answers the outstanding request.
+	s.stopTimerLocked()
+	s.pending = nil
+	return nil
+}
+
+func (s *StreamSession) awaitInput(req *InputRequest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stopTimerLocked()
+	s.pending = req
+	if s.opts.InputTimeout <= 0 {
+		return
+	}
+	s.timer = time.AfterFunc(s.opts.InputTimeout, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.pending != req {
+			return
+		}
+		s.timedOut = true
+		cancel, _ := json.Marshal(sessionFrame{
+			Type:        "cancel",
+			InterruptID: req.ID,
+			Data:        map[string]interface{}{"reason": "input_timeout"},
+		})
+		s.conn.WriteMessage(websocket.TextMessage, cancel)
+		// Unblocks any pending read; the iterator marks itself closed there.
+		s.conn.Close()
+	})
+}
+
+func (s *StreamSession) clearPending() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopTimerLocked()
+	s.pending = nil
+}
+
+func (s *StreamSession) stopTimerLocked() {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+}
+
+func (s *StreamSession) timeoutError() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.timedOut {
+		return nil
+	}
+	return newInputTimeoutError(s.pending)
+}
+
+// newInputTimeoutError reports a session closed for lack of human input. It
+// is a connection error like other deadlines: the input was never invalid.
+func newInputTimeoutError(req *InputRequest) *RunAgentError {
+	details := map[string]interface{}{}
+	if req != nil && req.ID != "" {
+		details["interrupt_id"] = req.ID
+	}
+	return newError(
+		ErrorTypeConnection,
+		"timed out waiting for human input",
+		withCode("INPUT_TIMEOUT"),
+		withDetails(details),
+		withSuggestion("Respond with Send/Resume/Approve before SessionOptions.InputTimeout elapses"),
+	)
+}
+
+// isInputRequiredFrame detects interrupt frames across the shapes servers emit.
+func isInputRequiredFrame(frameType, status string, payload interface{}) bool {
+	switch frameType {
+	case "interrupt", "input_required":
+		return true
+	}
+	switch status {
+	case "interrupt", "interrupted", "input_required", "awaiting_input":
+		return true
+	}
+	if m, ok := payload.(map[string]interface{}); ok {
+		if _, ok := m["__interrupt__"]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func parseInputRequest(payload interface{}) *InputRequest {
+	req := &InputRequest{
+		Payload:    payload,
+		ReceivedAt: time.Now(),
+	}
+
+	m, ok := payload.(map[string]interface{})
+	if !ok {
+		if str, ok := payload.(string); ok {
+			req.Prompt = str
+		}
+		return req
+	}
+
+	// LangGraph: {"__interrupt__": [{"value": ..., "id": ...}]}
+	if raw, ok := m["__interrupt__"]; ok {
+		if list, ok := raw.([]interface{}); ok && len(list) > 0 {
+			raw = list[0]
+		}
+		if inner, ok := raw.(map[string]interface{}); ok {
+			m = inner
+			if value, exists := inner["value"]; exists {
+				req.Payload = value
+			}
+		}
+	}
+
+	for _, key := range []string{"interrupt_id", "id"} {
+		if id, ok := m[key]; ok && id != nil {
+			req.ID = fmt.Sprint(id)
+			break
+		}
+	}
+
+	source := m
+	if value, ok := req.Payload.(map[string]interface{}); ok {
+		source = value
+	} else if str, ok := req.Payload.(string); ok {
+		req.Prompt = str
+	}
+	for _, key := range []string{"prompt", "question", "message"} {
+		if prompt, ok := source[key].(string); ok && strings.TrimSpace(prompt) != "" {
+			req.Prompt = prompt
+			break
+		}
+	}
+
+	return req
+}
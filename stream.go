@@ -4,157 +4,168 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// StreamEventKind is the normalized category of a stream frame.
+type StreamEventKind string
 
-	"github.com/gorilla/websocket"
+const (
+	StreamEventStatus    StreamEventKind = "status"
+	StreamEventData      StreamEventKind = "data"
+	StreamEventError     StreamEventKind = "error"
+	StreamEventCompleted StreamEventKind = "completed"
+	// StreamEventInputRequired marks frames where the agent paused and is
+	// waiting for a human response (e.g. a LangGraph interrupt).
+	StreamEventInputRequired StreamEventKind = "input_required"
 )
 
-// StreamIterator provides a blocking iterator over streaming responses.
+// StreamEvent exposes a single stream frame together with its metadata.
+type StreamEvent struct {
+	Kind      StreamEventKind
+	Type      string
+	Status    string
+	Sequence  int64
+	Timestamp time.Time
+	RunID     string
+	Payload   interface{}
+	Raw       json.RawMessage
+	Err       error
+	Input     *InputRequest
+}
+
+// StreamIterator provides a blocking iterator over streaming responses,
+// independent of the transport that delivers the frames.
 type StreamIterator struct {
-	conn   *websocket.Conn
-	closed bool
+	reader frameReader
+	// closed is atomic: sessions check it while another goroutine reads.
+	closed atomic.Bool
+
+	requestID string
+	runID     string
+
+	// onEvent observes every decoded event, e.g. to record transcripts. An
+	// error ends the stream and is returned from NextEvent.
+	onEvent func(*StreamEvent) error
+	// onClose runs once when the stream is closed, e.g. to release quotas.
+	onClose func()
 }
 
-func newStreamIterator(conn *websocket.Conn) *StreamIterator {
-	return &StreamIterator{conn: conn}
+func newStreamIterator(reader frameReader) *StreamIterator {
+	return &StreamIterator{reader: reader}
 }
 
 // Next blocks until the next chunk is available. The boolean indicates whether more data is expected.
+// Status frames are skipped; use NextEvent to observe them.
 func (s *StreamIterator) Next(ctx context.Context) (interface{}, bool, error) {
-	if s.closed {
-		return nil, false, nil
-	}
-
 	for {
-		select {
-		case <-ctx.Done():
-			s.Close()
-			return nil, false, ctx.Err()
-		default:
+		event, more, err := s.NextEvent(ctx)
+		if event != nil && event.Kind == StreamEventError {
+			// Error frames panic immediately with a friendly message.
+			panic(formatFriendlyError(event.Err))
 		}
-
-		_, msg, err := s.conn.ReadMessage()
-		if err != nil {
-			s.Close()
-			return nil, false, newError(
-				ErrorTypeConnection,
-				"failed to read stream message",
-				withCause(err),
-			)
-		}
-		var frame streamFrame
-		if err := json.Unmarshal(msg, &frame); err != nil {
-			s.Close()
-			return nil, false, newError(ErrorTypeServer, "invalid stream message", withCause(err))
+		if err != nil || !more {
+			return nil, false, err
 		}
+		if event.Kind == StreamEventStatus {
+			continue
+		}
+		if event.Kind == StreamEventInputRequired && event.Type == "status" {
+			continue
+		}
+		return event.Payload, true, nil
+	}
+}
 
-		// Uniform error detection across frame shapes - panic immediately on error frames
-		if len(frame.Error) > 0 && string(frame.Error) != "null" {
-			err := newExecutionError(0, enrichErrorPayload(parseFrameError(frame)))
-			s.Close()
-			panic(formatFriendlyError(err))
+// NextMessages blocks until the next data chunk and decodes it into chat
+// message deltas; accumulate them with Message.Append. Status and
+// input-required frames are skipped, and error frames are returned as errors.
+func (s *StreamIterator) NextMessages(ctx context.Context) ([]Message, bool, error) {
+	for {
+		event, more, err := s.NextEvent(ctx)
+		if err != nil || !more {
+			return nil, false, err
 		}
-		if strings.EqualFold(frame.Type, "error") {
-			err := newExecutionError(0, enrichErrorPayload(parseFrameError(frame)))
-			s.Close()
-			panic(formatFriendlyError(err))
-		}
-		// Detect status strings that indicate failure - panic immediately
-		if frame.Status != "" {
-			status := strings.ToLower(frame.Status)
-			if strings.Contains(status, "error") || strings.Contains(status, "fail") || strings.Contains(status, "failed") {
-				err := newExecutionError(0, enrichErrorPayload(parseFrameError(frame)))
-				s.Close()
-				panic(formatFriendlyError(err))
-			}
+		if event.Kind == StreamEventData {
+			return event.Messages(), true, nil
 		}
+	}
+}
 
-		switch strings.ToLower(frame.Type) {
-		case "status":
-			status := strings.ToLower(frame.Status)
-			switch status {
-			case "stream_started":
-				continue
-			case "error", "stream_error", "failed", "stream_failed":
-				err := newExecutionError(0, enrichErrorPayload(parseFrameError(frame)))
-				s.Close()
-				panic(formatFriendlyError(err))
-			case "stream_completed":
-				s.Close()
-				return nil, false, nil
-			default:
-				continue
-			}
-		case "error":
-			err := newExecutionError(0, enrichErrorPayload(parseFrameError(frame)))
+// NextEvent blocks until the next frame is available and returns it with its
+// metadata, including intermediate status frames. The boolean indicates
+// whether more events are expected. Error frames are returned as an event of
+// kind StreamEventError alongside the corresponding *RunAgentExecutionError.
+func (s *StreamIterator) NextEvent(ctx context.Context) (*StreamEvent, bool, error) {
+	if s.closed.Load() {
+		return nil, false, nil
+	}
+
+	select {
+	case <-ctx.Done():
+		s.Close()
+		return nil, false, ctx.Err()
+	default:
+	}
+
+	msg, err := s.reader.ReadFrame()
+	if err != nil {
+		s.Close()
+		return nil, false, newError(
+			ErrorTypeConnection,
+			"failed to read stream message",
+			withCause(err),
+		)
+	}
+
+	event, err := decodeStreamEvent(msg)
+	if err != nil {
+		s.Close()
+		return nil, false, err
+	}
+	if s.runID == "" && event.RunID != "" {
+		s.runID = event.RunID
+	}
+	if event.Err != nil {
+		event.Err = attachRequestIDs(event.Err, s.requestID, s.runID)
+	}
+	if s.onEvent != nil {
+		if err := s.onEvent(event); err != nil {
 			s.Close()
-			panic(formatFriendlyError(err))
-		case "data":
-			payload, err := decodeStreamPayload(frame)
-			if err != nil {
-				s.Close()
-				return nil, false, err
-			}
-			// If the payload itself encodes an error object, panic immediately
-			if m, ok := payload.(map[string]interface{}); ok {
-				// Some servers put error info inside the data envelope
-				if rawErr, ok := m["error"]; ok && rawErr != nil {
-					api := enrichErrorPayload(parseAPIError(rawErr))
-					err := newExecutionError(0, api)
-					s.Close()
-					panic(formatFriendlyError(err))
-				}
-				if t, ok := m["type"].(string); ok && strings.EqualFold(t, "error") {
-					// try to lift message/suggestion if present
-					api := &apiErrorPayload{
-						Type:    ErrorTypeServer,
-						Message: fmt.Sprint(m["message"]),
-						Code:    fmt.Sprint(m["code"]),
-					}
-					err := newExecutionError(0, enrichErrorPayload(api))
-					s.Close()
-					panic(formatFriendlyError(err))
-				}
-			}
-			return payload, true, nil
-		default:
-			// Treat unknown types as data for forward compatibility.
-			payload, err := decodeStreamPayload(frame)
-			if err != nil {
-				s.Close()
-				return nil, false, err
-			}
-			// Also inspect unknown payloads for embedded errors - panic immediately
-			if m, ok := payload.(map[string]interface{}); ok {
-				if rawErr, ok := m["error"]; ok && rawErr != nil {
-					api := enrichErrorPayload(parseAPIError(rawErr))
-					err := newExecutionError(0, api)
-					s.Close()
-					panic(formatFriendlyError(err))
-				}
-				if t, ok := m["type"].(string); ok && strings.EqualFold(t, "error") {
-					api := &apiErrorPayload{
-						Type:    ErrorTypeServer,
-						Message: fmt.Sprint(m["message"]),
-						Code:    fmt.Sprint(m["code"]),
-					}
-					err := newExecutionError(0, enrichErrorPayload(api))
-					s.Close()
-					panic(formatFriendlyError(err))
-				}
-			}
-			return payload, true, nil
+			return event, false, err
 		}
 	}
+
+	switch event.Kind {
+	case StreamEventError:
+		s.Close()
+		return event, false, event.Err
+	case StreamEventCompleted:
+		s.Close()
+		return event, false, nil
+	default:
+		return event, true, nil
+	}
 }
 
-// Close terminates the underlying WebSocket connection.
+// RequestID returns the client-generated request ID sent with the stream.
+func (s *StreamIterator) RequestID() string { return s.requestID }
+
+// RunID returns the server's run ID once a frame carrying it has been read.
+func (s *StreamIterator) RunID() string { return s.runID }
+
+// Close terminates the underlying transport connection.
 func (s *StreamIterator) Close() error {
-	if s.closed {
+	if s.closed.Swap(true) {
 		return nil
 	}
-	s.closed = true
-	return s.conn.Close()
+	if s.onClose != nil {
+		s.onClose()
+	}
+	return s.reader.Close()
 }
 
 // NextOrPanic is a convenience wrapper that panics on error with a user-friendly message.
@@ -170,6 +181,144 @@ func (s *StreamIterator) NextOrPanic(ctx context.Context) interface{} {
 	return chunk
 }
 
+// Messages decodes a data event into chat message deltas. Unlike Payload,
+// it reads the whole chunk, so tool call chunks sent beside "content" are
+// kept.
+func (e *StreamEvent) Messages() []Message {
+	if e.Kind != StreamEventData {
+		return nil
+	}
+	var frame streamFrame
+	if err := json.Unmarshal(e.Raw, &frame); err != nil {
+		return ParseMessages(e.Payload)
+	}
+	raw := frame.Content
+	if len(raw) == 0 {
+		raw = frame.Data
+	}
+	var chunk interface{}
+	if err := json.Unmarshal(raw, &chunk); err != nil {
+		return ParseMessages(e.Payload)
+	}
+	// Only decode strings that hold an object or list: a token such as
+	// " 42" must stay text.
+	if str, ok := chunk.(string); ok {
+		if trimmed := strings.TrimSpace(str); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+			chunk = decodeStructuredString(str)
+		}
+	}
+	if m, ok := chunk.(map[string]interface{}); ok {
+		chunk = decodeStructuredObject(m)
+	}
+	return ParseMessages(chunk)
+}
+
+// decodeStreamEvent parses a raw frame and classifies it. Error frames are
+// reported through the returned event; the error result is reserved for
+// frames that cannot be decoded at all.
+func decodeStreamEvent(msg []byte) (*StreamEvent, error) {
+	var frame streamFrame
+	if err := json.Unmarshal(msg, &frame); err != nil {
+		return nil, newError(ErrorTypeServer, "invalid stream message", withCause(err))
+	}
+
+	event := &StreamEvent{
+		Type:      strings.ToLower(frame.Type),
+		Status:    frame.Status,
+		Sequence:  parseFrameSequence(frame.Sequence),
+		Timestamp: parseFrameTimestamp(frame.Timestamp),
+		RunID:     frame.RunID,
+		Raw:       append(json.RawMessage(nil), msg...),
+	}
+	failed := func(apiErr *apiErrorPayload) (*StreamEvent, error) {
+		event.Kind = StreamEventError
+		event.Err = newExecutionError(0, enrichErrorPayload(apiErr))
+		return event, nil
+	}
+
+	// Uniform error detection across frame shapes
+	if len(frame.Error) > 0 && string(frame.Error) != "null" {
+		return failed(parseFrameError(frame))
+	}
+	if event.Type == "error" {
+		return failed(parseFrameError(frame))
+	}
+	// Detect status strings that indicate failure
+	status := strings.ToLower(frame.Status)
+	if strings.Contains(status, "error") || strings.Contains(status, "fail") {
+		return failed(parseFrameError(frame))
+	}
+
+	payload, err := decodeStreamPayload(frame)
+	if err != nil {
+		return nil, err
+	}
+	event.Payload = payload
+
+	if isInputRequiredFrame(event.Type, status, payload) {
+		event.Kind = StreamEventInputRequired
+		event.Input = parseInputRequest(payload)
+		return event, nil
+	}
+
+	if event.Type == "status" {
+		if status == "stream_completed" {
+			event.Kind = StreamEventCompleted
+		} else {
+			event.Kind = StreamEventStatus
+		}
+		return event, nil
+	}
+
+	// Data frames and unknown types (treated as data for forward
+	// compatibility) may still carry an error inside the payload.
+	if m, ok := payload.(map[string]interface{}); ok {
+		if rawErr, ok := m["error"]; ok && rawErr != nil {
+			return failed(parseAPIError(rawErr))
+		}
+		if t, ok := m["type"].(string); ok && strings.EqualFold(t, "error") {
+			return failed(&apiErrorPayload{
+				Type:    ErrorTypeServer,
+				Message: fmt.Sprint(m["message"]),
+				Code:    fmt.Sprint(m["code"]),
+			})
+		}
+	}
+
+	event.Kind = StreamEventData
+	return event, nil
+}
+
+// parseFrameSequence accepts both numeric and stringified sequence numbers.
+func parseFrameSequence(raw json.RawMessage) int64 {
+	str := strings.Trim(string(raw), `"`)
+	if seq, err := strconv.ParseInt(str, 10, 64); err == nil {
+		return seq
+	}
+	return 0
+}
+
+// parseFrameTimestamp accepts RFC 3339 strings as well as Unix seconds.
+func parseFrameTimestamp(raw json.RawMessage) time.Time {
+	if len(raw) == 0 || string(raw) == "null" {
+		return time.Time{}
+	}
+
+	var str string
+	if err := json.Unmarshal(raw, &str); err == nil {
+		if ts, err := time.Parse(time.RFC3339Nano, str); err == nil {
+			return ts
+		}
+		raw = json.RawMessage(str)
+	}
+
+	if secs, err := strconv.ParseFloat(string(raw), 64); err == nil {
+		whole := int64(secs)
+		return time.Unix(whole, int64((secs-float64(whole))*float64(time.Second)))
+	}
+	return time.Time{}
+}
+
 func decodeStreamPayload(frame streamFrame) (interface{}, error) {
 	raw := frame.Content
 	if len(raw) == 0 {
@@ -209,8 +358,18 @@ func decodeStreamPayload(frame streamFrame) (interface{}, error) {
 	}
 }
 
+// parseFrameError reads the error of a failed frame. Without an "error"
+// field it falls back to the frame's data or content, which is where SSE
+// "event: error" payloads end up once wrapped by normalizeFrame.
 func parseFrameError(frame streamFrame) *apiErrorPayload {
-	if len(frame.Error) == 0 {
+	raw := frame.Error
+	if len(raw) == 0 || string(raw) == "null" {
+		raw = frame.Data
+		if len(raw) == 0 || string(raw) == "null" {
+			raw = frame.Content
+		}
+	}
+	if len(raw) == 0 || string(raw) == "null" {
 		return &apiErrorPayload{
 			Type:    ErrorTypeServer,
 			Message: "stream failed",
@@ -218,12 +377,23 @@ func parseFrameError(frame streamFrame) *apiErrorPayload {
 	}
 
 	var payload interface{}
-	if err := json.Unmarshal(frame.Error, &payload); err != nil {
+	if err := json.Unmarshal(raw, &payload); err != nil {
 		return &apiErrorPayload{
 			Type:    ErrorTypeServer,
-			Message: fmt.Sprintf("stream error: %s", string(frame.Error)),
+			Message: fmt.Sprintf("stream error: %s", string(raw)),
 		}
 	}
+	if m, ok := payload.(map[string]interface{}); ok && m["error"] != nil {
+		payload = m["error"]
+	}
 
-	return parseAPIError(payload)
+	apiErr := parseAPIError(payload)
+	if apiErr == nil || apiErr.Message == "" {
+		fallback := &apiErrorPayload{Type: ErrorTypeServer, Message: "stream failed"}
+		if apiErr != nil {
+			fallback.Code = apiErr.Code
+		}
+		return fallback
+	}
+	return apiErr
 }
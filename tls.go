@@ -0,0 +1,176 @@
+package runagent
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// TLSConfig configures server verification and client certificates for
+// self-hosted backends. It applies to REST calls and WebSocket streams alike.
+type TLSConfig struct {
+	// CAFile is a PEM bundle trusted in addition to the system roots.
+	CAFile string
+	// CAPEM is an in-memory PEM bundle, appended after CAFile.
+	CAPEM []byte
+	// CertFile and KeyFile hold the client certificate for mTLS.
+	CertFile string
+	KeyFile  string
+	// ServerName overrides the name used for SNI and verification.
+	ServerName string
+	// InsecureSkipVerify disables server verification; for testing only.
+	InsecureSkipVerify bool
+}
+
+// buildTLSConfig turns TLSConfig into a *tls.Config.
+func buildTLSConfig(cfg TLSConfig) (*tls.Config, error) {
+	tlsCfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if cfg.CAFile != "" || len(cfg.CAPEM) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if cfg.CAFile != "" {
+			pem, err := os.ReadFile(cfg.CAFile)
+			if err != nil {
+				return nil, newTLSConfigError("failed to read CA bundle", err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, newTLSConfigError(fmt.Sprintf("no certificates found in %s", cfg.CAFile), nil)
+			}
+		}
+		if len(cfg.CAPEM) > 0 && !pool.AppendCertsFromPEM(cfg.CAPEM) {
+			return nil, newTLSConfigError("no certificates found in CAPEM", nil)
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		if cfg.CertFile == "" || cfg.KeyFile == "" {
+			return nil, newTLSConfigError("client certificate requires both CertFile and KeyFile", nil)
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, newTLSConfigError("failed to load client certificate", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+	return tlsCfg, nil
+}
+
+func newTLSConfigError(message string, cause error) *RunAgentError {
+	opts := []func(*RunAgentError){
+		withCode("TLS_CONFIG"),
+		withSuggestion("Check Config.TLS paths and PEM contents"),
+	}
+	if cause != nil {
+		opts = append(opts, withCause(cause))
+	}
+	return newError(ErrorTypeValidation, message, opts...)
+}
+
+// resolveProxy returns the proxy function for an explicit proxy URL, or the
+// HTTPS_PROXY/HTTP_PROXY/NO_PROXY environment when it is empty.
+func resolveProxy(proxy string) (func(*http.Request) (*url.URL, error), error) {
+	if proxy == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+	proxyURL, err := url.Parse(proxy)
+	if err != nil || proxyURL.Host == "" {
+		return nil, newError(
+			ErrorTypeValidation,
+			fmt.Sprintf("invalid proxy URL %q", proxy),
+			withCode("INVALID_PROXY"),
+			withSuggestion("Use a URL such as http://proxy.internal:3128"),
+		)
+	}
+	return http.ProxyURL(proxyURL), nil
+}
+
+// buildHTTPClient applies TLS, proxy and Unix socket settings. A
+// caller-supplied client is copied rather than modified; its transport must
+// be an *http.Transport when any of them is set.
+func buildHTTPClient(base *http.Client, timeout time.Duration, tlsCfg *TLSConfig, proxy, socketPath string) (*http.Client, error) {
+	if base != nil && tlsCfg == nil && proxy == "" && socketPath == "" {
+		return base, nil
+	}
+
+	client := &http.Client{Timeout: timeout}
+	if base != nil {
+		copied := *base
+		client = &copied
+	}
+
+	var transport *http.Transport
+	switch t := client.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = t.Clone()
+	default:
+		return nil, newError(
+			ErrorTypeValidation,
+			"Config.TLS, Config.Proxy and Config.SocketPath require HTTPClient.Transport to be an *http.Transport",
+			withCode("TLS_CONFIG"),
+			withSuggestion("Configure your own transport instead, or drop Config.HTTPClient"),
+		)
+	}
+
+	if tlsCfg != nil {
+		built, err := buildTLSConfig(*tlsCfg)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = built
+	}
+	proxyFunc, err := resolveProxy(proxy)
+	if err != nil {
+		return nil, err
+	}
+	transport.Proxy = proxyFunc
+
+	if socketPath != "" {
+		// Socket traffic never leaves the machine, so bypass any proxy.
+		var dialer net.Dialer
+		transport.Proxy = nil
+		transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			// Requests are addressed to http://localhost; anything else, such
+			// as an absolute URL from the server, gets a normal connection.
+			if addr != "localhost:80" {
+				return dialer.DialContext(ctx, network, addr)
+			}
+			return dialer.DialContext(ctx, "unix", socketPath)
+		}
+	}
+
+	client.Transport = transport
+	return client, nil
+}
+
+// newWebSocketDialer mirrors the HTTP client's TLS, proxy and dial settings
+// so streams reach the same backend the REST calls do.
+func newWebSocketDialer(client *http.Client) *websocket.Dialer {
+	dialer := &websocket.Dialer{
+		HandshakeTimeout: 30 * time.Second,
+		Proxy:            http.ProxyFromEnvironment,
+	}
+	if transport, ok := client.Transport.(*http.Transport); ok {
+		dialer.TLSClientConfig = transport.TLSClientConfig
+		dialer.Proxy = transport.Proxy
+		dialer.NetDialContext = transport.DialContext
+	}
+	return dialer
+}
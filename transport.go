@@ -0,0 +1,341 @@
+package runagent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+// StreamTransport selects how RunStream receives frames from the service.
+type StreamTransport string
+
+const (
+	// StreamTransportAuto tries WebSocket first and falls back to SSE, then NDJSON.
+	StreamTransportAuto      StreamTransport = "auto"
+	StreamTransportWebSocket StreamTransport = "websocket"
+	StreamTransportSSE       StreamTransport = "sse"
+	StreamTransportNDJSON    StreamTransport = "ndjson"
+)
+
+const (
+	contentTypeSSE    = "text/event-stream"
+	contentTypeNDJSON = "application/x-ndjson"
+)
+
+// completedFrame is synthesized when an HTTP stream ends cleanly without an
+// explicit stream_completed status, so all transports terminate the same way.
+var completedFrame = []byte(`{"type":"status","status":"stream_completed"}`)
+
+// frameReader yields raw JSON stream frames from a transport.
+type frameReader interface {
+	ReadFrame() ([]byte, error)
+	Close() error
+}
+
+// resolveStreamTransports expands the configured transport into the ordered
+// list of transports RunStream attempts.
+func resolveStreamTransports(raw StreamTransport) ([]StreamTransport, error) {
+	switch StreamTransport(strings.ToLower(strings.TrimSpace(string(raw)))) {
+	case "", StreamTransportAuto:
+		return []StreamTransport{StreamTransportWebSocket, StreamTransportSSE, StreamTransportNDJSON}, nil
+	case StreamTransportWebSocket, "ws":
+		return []StreamTransport{StreamTransportWebSocket}, nil
+	case StreamTransportSSE:
+		return []StreamTransport{StreamTransportSSE}, nil
+	case StreamTransportNDJSON:
+		return []StreamTransport{StreamTransportNDJSON}, nil
+	default:
+		return nil, newError(
+			ErrorTypeValidation,
+			fmt.Sprintf("unsupported stream transport: %s", raw),
+			withSuggestion("Use one of: auto, websocket, sse, ndjson"),
+		)
+	}
+}
+
+// openStream opens the stream using the configured transports in order,
+// falling back to the next one when a transport cannot be established.
+func (c *RunAgentClient) openStream(ctx context.Context, payload apiRunRequest) (frameReader, error) {
+	var lastErr error
+	for _, transport := range c.streamTransports {
+		var reader frameReader
+		var err error
+		switch transport {
+		case StreamTransportWebSocket:
+			var conn *websocket.Conn
+			conn, err = c.dialWebSocket(ctx, payload)
+			if err == nil {
+				reader = wsFrameReader{conn: conn}
+			}
+		default:
+			reader, err = c.openHTTPStream(ctx, payload, transport)
+		}
+		if err == nil {
+			return reader, nil
+		}
+		fallback := isTransportFallbackError(err) ||
+			(transport == StreamTransportWebSocket && isRejectedHandshake(err))
+		if !fallback || ctx.Err() != nil {
+			return nil, err
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// isRejectedHandshake reports whether a WebSocket upgrade was answered with
+// an HTTP error other than 401. Egress proxies commonly refuse upgrades with
+// 403, 407 or 502/503 while still passing plain HTTP, so these fall back to
+// the HTTP transports; a 401 would fail there too.
+func isRejectedHandshake(err error) bool {
+	var execErr *RunAgentExecutionError
+	return errors.As(err, &execErr) &&
+		execErr.HTTPStatus != 0 && execErr.HTTPStatus != http.StatusUnauthorized
+}
+
+// isTransportFallbackError reports whether another transport may succeed
+// where this one failed. Authentication and execution errors are final.
+func isTransportFallbackError(err error) bool {
+	var execErr *RunAgentExecutionError
+	if errors.As(err, &execErr) {
+		switch execErr.HTTPStatus {
+		case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotAcceptable,
+			http.StatusUnsupportedMediaType, http.StatusUpgradeRequired:
+			return true
+		}
+		return false
+	}
+	if runErr, ok := AsRunAgentError(err); ok {
+		return runErr.Type == ErrorTypeConnection
+	}
+	return false
+}
+
+// openHTTPStream POSTs the bootstrap payload to the run-stream endpoint and
+// reads the response as Server-Sent Events or newline-delimited JSON,
+// depending on the Content-Type the server negotiates.
+func (c *RunAgentClient) openHTTPStream(ctx context.Context, payload apiRunRequest, transport StreamTransport) (frameReader, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, newError(ErrorTypeValidation, "failed to serialize stream payload", withCause(err))
+	}
+
+	endpoint := fmt.Sprintf("%s/agents/%s/run-stream", c.baseRESTURL, c.agentID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, newError(ErrorTypeUnknown, "failed to create request", withCause(err))
+	}
+
+	accept := contentTypeSSE
+	if transport == StreamTransportNDJSON {
+		accept = contentTypeNDJSON
+	}
+	req.Header.Set("Accept", accept)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent())
+	setRequestIDHeaders(req.Header, payload.RequestID, payload.IdempotencyKey)
+	if err := c.applyAuth(req); err != nil {
+		return nil, err
+	}
+
+	// Streams outlive the REST timeout; cancellation is driven by ctx instead.
+	streamClient := *c.httpClient
+	streamClient.Timeout = 0
+
+	resp, err := c.doAuthorized(&streamClient, req)
+	if err != nil {
+		return nil, newError(
+			ErrorTypeConnection,
+			fmt.Sprintf("failed to open %s stream", transport),
+			withCause(err),
+		)
+	}
+
+	c.rateLimiter.observe(resp)
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, translateHTTPError(resp.StatusCode, resp.Header, respBody)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch mediaType {
+	case contentTypeSSE:
+		return newSSEFrameReader(resp.Body), nil
+	case contentTypeNDJSON, "application/jsonl", "application/json":
+		return newNDJSONFrameReader(resp.Body), nil
+	default:
+		resp.Body.Close()
+		return nil, newError(
+			ErrorTypeConnection,
+			fmt.Sprintf("unexpected stream content type %q", mediaType),
+			withSuggestion("Ensure the server supports the selected stream transport"),
+		)
+	}
+}
+
+type wsFrameReader struct {
+	conn *websocket.Conn
+}
+
+func (r wsFrameReader) ReadFrame() ([]byte, error) {
+	_, msg, err := r.conn.ReadMessage()
+	return msg, err
+}
+
+func (r wsFrameReader) Close() error {
+	return r.conn.Close()
+}
+
+// sseFrameReader parses a text/event-stream body into JSON frames. The SSE
+// event name is used as the frame type when the data does not carry one.
+type sseFrameReader struct {
+	body io.ReadCloser
+	buf  *bufio.Reader
+	done bool
+}
+
+func newSSEFrameReader(body io.ReadCloser) *sseFrameReader {
+	return &sseFrameReader{body: body, buf: bufio.NewReader(body)}
+}
+
+func (r *sseFrameReader) ReadFrame() ([]byte, error) {
+	if r.done {
+		return nil, io.EOF
+	}
+
+	var event string
+	var data []string
+	for {
+		line, err := r.buf.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		atEOF := err != nil
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, ":"):
+			// Comment / keep-alive.
+			if !atEOF {
+				continue
+			}
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+		if line != "" && !atEOF {
+			continue
+		}
+
+		// Blank line or end of body: dispatch the buffered event.
+		if len(data) == 0 {
+			if atEOF {
+				r.done = true
+				return completedFrame, nil
+			}
+			event = ""
+			continue
+		}
+		joined := strings.Join(data, "\n")
+		if joined == "[DONE]" {
+			r.done = true
+			return completedFrame, nil
+		}
+		return normalizeFrame(event, []byte(joined)), nil
+	}
+}
+
+func (r *sseFrameReader) Close() error {
+	r.done = true
+	return r.body.Close()
+}
+
+// ndjsonFrameReader reads one JSON frame per line from a chunked HTTP body.
+type ndjsonFrameReader struct {
+	body io.ReadCloser
+	buf  *bufio.Reader
+	done bool
+}
+
+func newNDJSONFrameReader(body io.ReadCloser) *ndjsonFrameReader {
+	return &ndjsonFrameReader{body: body, buf: bufio.NewReader(body)}
+}
+
+func (r *ndjsonFrameReader) ReadFrame() ([]byte, error) {
+	if r.done {
+		return nil, io.EOF
+	}
+	for {
+		line, err := r.buf.ReadBytes('\n')
+		trimmed := bytes.TrimSpace(line)
+		if len(trimmed) > 0 {
+			return normalizeFrame("", trimmed), nil
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				r.done = true
+				return completedFrame, nil
+			}
+			return nil, err
+		}
+	}
+}
+
+func (r *ndjsonFrameReader) Close() error {
+	r.done = true
+	return r.body.Close()
+}
+
+// normalizeFrame ensures HTTP transports yield the same frame shape as the
+// WebSocket transport: a JSON object with a "type" field.
+func normalizeFrame(eventType string, data []byte) []byte {
+	var obj map[string]interface{}
+	if err := json.Unmarshal(data, &obj); err == nil {
+		if _, ok := obj["type"]; ok {
+			return data
+		}
+		if isFrameObject(obj) {
+			if eventType == "" {
+				eventType = "data"
+			}
+			obj["type"] = eventType
+			if out, err := json.Marshal(obj); err == nil {
+				return out
+			}
+			return data
+		}
+	}
+
+	if eventType == "" {
+		eventType = "data"
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		value = string(data)
+	}
+	out, _ := json.Marshal(map[string]interface{}{"type": eventType, "data": value})
+	return out
+}
+
+// isFrameObject reports whether obj already looks like a stream frame rather
+// than a bare payload.
+func isFrameObject(obj map[string]interface{}) bool {
+	for _, key := range []string{"status", "content", "data", "error"} {
+		if _, ok := obj[key]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -21,6 +21,41 @@ type Config struct {
 	AsyncExecution *bool
 	ExtraParams    map[string]interface{}
 	HTTPClient     *http.Client
+	// TLS configures custom CAs and client certificates for REST and
+	// WebSocket connections.
+	TLS *TLSConfig
+	// Proxy is an explicit proxy URL. When empty, HTTPS_PROXY, HTTP_PROXY
+	// and NO_PROXY are honored.
+	Proxy string
+	// StreamTransport selects the RunStream transport; defaults to auto,
+	// which falls back from WebSocket to SSE and then NDJSON.
+	StreamTransport StreamTransport
+	// MaxAttachmentBytes caps attachment uploads and downloads; defaults to 50 MiB.
+	MaxAttachmentBytes int64
+	// RateLimit throttles runs for this agent ID and entrypoint across all
+	// clients in the process.
+	RateLimit *RateLimit
+	// CircuitBreaker fails fast with CIRCUIT_OPEN while the agent is unhealthy.
+	CircuitBreaker *CircuitBreakerConfig
+	// Cache serves repeated identical runs and architecture lookups from a
+	// cache; only enable it for deterministic entrypoints.
+	Cache *CacheConfig
+	// Profile selects a named profile from ~/.runagent/user_data.json. It
+	// falls back to RUNAGENT_PROFILE, then the file's default_profile.
+	Profile string
+	// Credentials supplies the API key per request, enabling rotation. An
+	// explicit APIKey takes precedence.
+	Credentials CredentialProvider
+	// SocketPath dials a local agent through a Unix socket instead of
+	// Host/Port. Defaults to RUNAGENT_SOCKET, then the registry entry.
+	SocketPath string
+	// Validation checks kwargs against the entrypoint's input schema before
+	// each run and rejects mismatches without a network call.
+	Validation *ValidationConfig
+	// Framework selects the adapter for Message and State inputs and for
+	// RunAgentClient.Messages. Defaults to the local registry's framework,
+	// then the agent's runagent.config.json.
+	Framework Framework
 }
 
 // RunInput describes a run invocation payload.
@@ -29,6 +64,16 @@ type RunInput struct {
 	InputKwargs    map[string]interface{}
 	TimeoutSeconds int
 	AsyncExecution *bool
+	// ThreadID ties the run to a server-side conversation thread.
+	ThreadID string
+	// IdempotencyKey lets the server deduplicate retried runs.
+	IdempotencyKey string
+	// BypassCache skips the cache lookup; the fresh result is still stored.
+	BypassCache bool
+	// Messages and State are shaped into args and kwargs by the client's
+	// framework adapter before the run is sent.
+	Messages []Message
+	State    map[string]interface{}
 }
 
 // StreamOptions allow customizing RunStream behavior.
@@ -45,6 +90,10 @@ type apiRunRequest struct {
 	InputKwargs    map[string]interface{} `json:"input_kwargs"`
 	TimeoutSeconds int                    `json:"timeout_seconds"`
 	AsyncExecution bool                   `json:"async_execution,omitempty"`
+	Interactive    bool                   `json:"interactive,omitempty"`
+	ThreadID       string                 `json:"thread_id,omitempty"`
+	RequestID      string                 `json:"request_id,omitempty"`
+	IdempotencyKey string                 `json:"idempotency_key,omitempty"`
 }
 
 type apiErrorPayload struct {
@@ -56,11 +105,14 @@ type apiErrorPayload struct {
 }
 
 type streamFrame struct {
-	Type    string          `json:"type"`
-	Status  string          `json:"status"`
-	Content json.RawMessage `json:"content"`
-	Data    json.RawMessage `json:"data"`
-	Error   json.RawMessage `json:"error"`
+	Type      string          `json:"type"`
+	Status    string          `json:"status"`
+	RunID     string          `json:"run_id"`
+	Sequence  json.RawMessage `json:"sequence"`
+	Timestamp json.RawMessage `json:"timestamp"`
+	Content   json.RawMessage `json:"content"`
+	Data      json.RawMessage `json:"data"`
+	Error     json.RawMessage `json:"error"`
 }
 
 // EntryPoint describes a deployable entrypoint.
@@ -71,6 +123,11 @@ type EntryPoint struct {
 	Name        string                 `json:"name,omitempty"`
 	Description string                 `json:"description,omitempty"`
 	Extractor   map[string]interface{} `json:"extractor,omitempty"`
+	// InputSchema is a JSON Schema object describing the entrypoint's kwargs.
+	InputSchema map[string]interface{} `json:"input_schema,omitempty"`
+	// Parameters lists the entrypoint's declared parameters; InputSchema
+	// takes precedence when both are present.
+	Parameters []EntryPointParameter `json:"parameters,omitempty"`
 }
 
 // AgentArchitecture provides entrypoint metadata for an agent.
@@ -106,6 +163,7 @@ func (i RunInput) toAPIPayload(entrypoint string, fallbackTimeout int, defaultAs
 		InputKwargs:    kwargs,
 		TimeoutSeconds: timeout,
 		AsyncExecution: async,
+		ThreadID:       i.ThreadID,
 	}
 }
 
@@ -0,0 +1,480 @@
+package runagent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ValidationConfig enables client-side validation of kwargs against the
+// entrypoint's input schema before a run is sent.
+type ValidationConfig struct {
+	// Schema is a JSON Schema object for the kwargs. It takes precedence over
+	// SchemaFile and the architecture.
+	Schema map[string]interface{}
+	// SchemaFile loads the schema from disk. It may be a JSON Schema file, a
+	// saved architecture, a runagent.config.json or an agent directory; all
+	// but the first are searched for the client's entrypoint tag.
+	SchemaFile string
+	// AllowUnknown accepts kwargs the schema does not declare. Otherwise
+	// they are rejected unless the schema sets additionalProperties.
+	AllowUnknown bool
+}
+
+// schemaRetryBackoff is how long validation is skipped after the
+// architecture lookup for the schema fails.
+const schemaRetryBackoff = 30 * time.Second
+
+// inputValidator resolves the schema once and checks each run's kwargs.
+// Without Schema or SchemaFile the schema comes from GetArchitecture; a
+// failed lookup skips validation and is retried after schemaRetryBackoff.
+type inputValidator struct {
+	cfg ValidationConfig
+
+	mu       sync.Mutex
+	resolved bool
+	schema   map[string]interface{}
+	// pending is closed when the in-flight architecture lookup finishes.
+	pending chan struct{}
+	retryAt time.Time
+}
+
+func newInputValidator(cfg *ValidationConfig, tag string) (*inputValidator, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	v := &inputValidator{cfg: *cfg}
+	switch {
+	case len(cfg.Schema) > 0:
+		v.schema, v.resolved = cfg.Schema, true
+	case cfg.SchemaFile != "":
+		schema, err := loadSchemaFile(cfg.SchemaFile, tag)
+		if err != nil {
+			return nil, err
+		}
+		v.schema, v.resolved = schema, true
+	}
+	return v, nil
+}
+
+// validateInput rejects runs the agent cannot accept: tags missing from a
+// discovered runagent.config.json, and kwargs that do not match the
+// entrypoint's schema when validation is enabled.
+func (c *RunAgentClient) validateInput(ctx context.Context, input RunInput) error {
+	if c.agentConfig != nil {
+		if err := c.agentConfig.CheckEntrypoint(c.entrypointTag); err != nil {
+			return err
+		}
+	}
+
+	v := c.validator
+	if v == nil {
+		return nil
+	}
+
+	schema := v.resolveSchema(ctx, c)
+	if schema == nil {
+		return nil
+	}
+	problems := checkKwargs(schema, input, v.cfg.AllowUnknown)
+	if len(problems) == 0 {
+		return nil
+	}
+	details := make([]interface{}, len(problems))
+	for i, p := range problems {
+		details[i] = p
+	}
+	return newError(
+		ErrorTypeValidation,
+		fmt.Sprintf("invalid input for %s: %s", c.entrypointTag, strings.Join(problems, "; ")),
+		withCode("INVALID_INPUT"),
+		withDetails(map[string]interface{}{"problems": details}),
+		withSuggestion("Fix the listed kwargs, or use GetArchitecture(ctx) to inspect the entrypoint's schema"),
+	)
+}
+
+// resolveSchema returns the entrypoint's schema, looking it up in the
+// architecture on first use. The lookup runs outside the lock and only once
+// at a time; concurrent runs wait for it instead of issuing their own.
+func (v *inputValidator) resolveSchema(ctx context.Context, c *RunAgentClient) map[string]interface{} {
+	for {
+		v.mu.Lock()
+		if v.resolved || time.Now().Before(v.retryAt) {
+			schema := v.schema
+			v.mu.Unlock()
+			return schema
+		}
+		if pending := v.pending; pending != nil {
+			v.mu.Unlock()
+			select {
+			case <-pending:
+				continue
+			case <-ctx.Done():
+				return nil
+			}
+		}
+		pending := make(chan struct{})
+		v.pending = pending
+		v.mu.Unlock()
+
+		arch, err := c.GetArchitecture(ctx)
+
+		v.mu.Lock()
+		switch {
+		case err == nil:
+			for _, ep := range arch.Entrypoints {
+				if ep.Tag == c.entrypointTag {
+					v.schema = ep.JSONSchema()
+					break
+				}
+			}
+			v.resolved = true
+		case ctx.Err() == nil:
+			// A cancelled run says nothing about the agent, so only real
+			// failures back off.
+			v.retryAt = time.Now().Add(schemaRetryBackoff)
+		}
+		schema := v.schema
+		v.pending = nil
+		close(pending)
+		v.mu.Unlock()
+		return schema
+	}
+}
+
+// checkKwargs validates kwargs against an object schema. Required kwargs
+// are not enforced when positional args are present, since they may fill
+// those parameters.
+func checkKwargs(schema map[string]interface{}, input RunInput, allowUnknown bool) []string {
+	kwargs := map[string]interface{}{}
+	if len(input.InputKwargs) > 0 {
+		// Round-trip through JSON so structs and typed values are checked in
+		// the form the agent receives.
+		if data, err := json.Marshal(input.InputKwargs); err == nil {
+			json.Unmarshal(data, &kwargs)
+		}
+	}
+
+	props, _ := schema["properties"].(map[string]interface{})
+	var problems []string
+
+	if len(input.InputArgs) == 0 {
+		for _, name := range schemaRequired(schema) {
+			if _, ok := kwargs[name]; !ok {
+				problems = append(problems, fmt.Sprintf("missing required kwarg %q", name))
+			}
+		}
+	}
+
+	names := make([]string, 0, len(kwargs))
+	for name := range kwargs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prop, declared := props[name].(map[string]interface{})
+		if !declared {
+			switch extra := schema["additionalProperties"].(type) {
+			case map[string]interface{}:
+				problems = append(problems, checkValue(name, extra, kwargs[name])...)
+			case bool:
+				if !extra && !allowUnknown {
+					problems = append(problems, unknownKwarg(name, props))
+				}
+			default:
+				if !allowUnknown && props != nil {
+					problems = append(problems, unknownKwarg(name, props))
+				}
+			}
+			continue
+		}
+		problems = append(problems, checkValue(name, prop, kwargs[name])...)
+	}
+	return problems
+}
+
+func unknownKwarg(name string, props map[string]interface{}) string {
+	if match := closestName(name, props); match != "" {
+		return fmt.Sprintf("unknown kwarg %q (did you mean %q?)", name, match)
+	}
+	return fmt.Sprintf("unknown kwarg %q", name)
+}
+
+// checkValue validates one decoded JSON value against a schema. It covers
+// the JSON Schema keywords agents declare in practice: type, enum, const,
+// required/properties/additionalProperties, items, numeric and length
+// bounds, and pattern.
+func checkValue(path string, schema map[string]interface{}, value interface{}) []string {
+	if len(schema) == 0 {
+		return nil
+	}
+
+	if types := schemaTypes(schema); len(types) > 0 {
+		actual := jsonTypeOf(value)
+		ok := false
+		for _, t := range types {
+			if t == actual || (t == "number" && actual == "integer") {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected %s, got %s", path, strings.Join(types, " or "), actual)}
+		}
+	}
+
+	var problems []string
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		found := false
+		for _, candidate := range enum {
+			if jsonEqual(candidate, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			problems = append(problems, fmt.Sprintf("%s: must be one of %s", path, compactJSON(enum)))
+		}
+	}
+	if constant, ok := schema["const"]; ok && !jsonEqual(constant, value) {
+		problems = append(problems, fmt.Sprintf("%s: must equal %s", path, compactJSON(constant)))
+	}
+
+	switch t := value.(type) {
+	case string:
+		length := float64(len([]rune(t)))
+		if min, ok := schemaNumber(schema, "minLength"); ok && length < min {
+			problems = append(problems, fmt.Sprintf("%s: shorter than %v characters", path, min))
+		}
+		if max, ok := schemaNumber(schema, "maxLength"); ok && length > max {
+			problems = append(problems, fmt.Sprintf("%s: longer than %v characters", path, max))
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(t) {
+				problems = append(problems, fmt.Sprintf("%s: does not match pattern %q", path, pattern))
+			}
+		}
+	case float64:
+		if min, ok := schemaNumber(schema, "minimum"); ok && t < min {
+			problems = append(problems, fmt.Sprintf("%s: must be >= %v", path, min))
+		}
+		if max, ok := schemaNumber(schema, "maximum"); ok && t > max {
+			problems = append(problems, fmt.Sprintf("%s: must be <= %v", path, max))
+		}
+	case []interface{}:
+		count := float64(len(t))
+		if min, ok := schemaNumber(schema, "minItems"); ok && count < min {
+			problems = append(problems, fmt.Sprintf("%s: fewer than %v items", path, min))
+		}
+		if max, ok := schemaNumber(schema, "maxItems"); ok && count > max {
+			problems = append(problems, fmt.Sprintf("%s: more than %v items", path, max))
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range t {
+				problems = append(problems, checkValue(fmt.Sprintf("%s[%d]", path, i), items, item)...)
+			}
+		}
+	case map[string]interface{}:
+		props, _ := schema["properties"].(map[string]interface{})
+		for _, name := range schemaRequired(schema) {
+			if _, ok := t[name]; !ok {
+				problems = append(problems, fmt.Sprintf("%s.%s: missing required field", path, name))
+			}
+		}
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if prop, ok := props[k].(map[string]interface{}); ok {
+				problems = append(problems, checkValue(path+"."+k, prop, t[k])...)
+				continue
+			}
+			switch extra := schema["additionalProperties"].(type) {
+			case map[string]interface{}:
+				problems = append(problems, checkValue(path+"."+k, extra, t[k])...)
+			case bool:
+				if !extra {
+					problems = append(problems, fmt.Sprintf("%s.%s: unknown field", path, k))
+				}
+			}
+		}
+	}
+	return problems
+}
+
+func schemaTypes(schema map[string]interface{}) []string {
+	switch t := schema["type"].(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		types := make([]string, 0, len(t))
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+func schemaRequired(schema map[string]interface{}) []string {
+	var names []string
+	switch t := schema["required"].(type) {
+	case []interface{}:
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				names = append(names, s)
+			}
+		}
+	case []string:
+		names = append(names, t...)
+	}
+	return names
+}
+
+func schemaNumber(schema map[string]interface{}, key string) (float64, bool) {
+	switch t := schema[key].(type) {
+	case float64:
+		return t, true
+	case int:
+		return float64(t), true
+	case int64:
+		return float64(t), true
+	}
+	return 0, false
+}
+
+func jsonTypeOf(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if t == math.Trunc(t) && !math.IsInf(t, 0) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func jsonEqual(a, b interface{}) bool {
+	return compactJSON(a) == compactJSON(b)
+}
+
+func compactJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// closestName suggests a declared property within two edits of name.
+func closestName(name string, props map[string]interface{}) string {
+	best, bestDist := "", 3
+	for candidate := range props {
+		d := editDistance(strings.ToLower(name), strings.ToLower(candidate))
+		if d >= len(name) {
+			continue
+		}
+		if d < bestDist || (d == bestDist && candidate < best) {
+			best, bestDist = candidate, d
+		}
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// loadSchemaFile reads a JSON Schema, or finds the tag's schema in a saved
+// architecture, a runagent.config.json or an agent directory.
+func loadSchemaFile(path, tag string) (map[string]interface{}, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		cfg, err := LoadAgentConfig(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := cfg.CheckEntrypoint(tag); err != nil {
+			return nil, err
+		}
+		ep, _ := cfg.Entrypoint(tag)
+		return ep.JSONSchema(), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, newError(ErrorTypeValidation, "failed to read validation schema", withCause(err))
+	}
+
+	var doc struct {
+		Entrypoints       []EntryPoint       `json:"entrypoints"`
+		Data              *AgentArchitecture `json:"data"`
+		AgentArchitecture *AgentArchitecture `json:"agent_architecture"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, newError(ErrorTypeValidation, "invalid validation schema file", withCause(err))
+	}
+	entrypoints := doc.Entrypoints
+	switch {
+	case doc.AgentArchitecture != nil:
+		entrypoints = doc.AgentArchitecture.Entrypoints
+	case doc.Data != nil:
+		entrypoints = doc.Data.Entrypoints
+	}
+	if len(entrypoints) > 0 {
+		for _, ep := range entrypoints {
+			if ep.Tag == tag {
+				return ep.JSONSchema(), nil
+			}
+		}
+		return nil, newError(
+			ErrorTypeValidation,
+			fmt.Sprintf("entrypoint %s not found in %s", tag, path),
+			withCode("ENTRYPOINT_NOT_FOUND"),
+		)
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, newError(ErrorTypeValidation, "invalid validation schema file", withCause(err))
+	}
+	return schema, nil
+}
@@ -0,0 +1,83 @@
+package runagent
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckKwargs(t *testing.T) {
+	schema := map[string]interface{}{
+		"type":     "object",
+		"required": []interface{}{"query"},
+		"properties": map[string]interface{}{
+			"query": map[string]interface{}{"type": "string"},
+			"limit": map[string]interface{}{"type": "integer"},
+			"score": map[string]interface{}{"type": []interface{}{"number", "null"}},
+			"mode":  map[string]interface{}{"type": "string", "enum": []interface{}{"fast", "exact"}},
+			"filter": map[string]interface{}{
+				"type":       "object",
+				"required":   []interface{}{"field"},
+				"properties": map[string]interface{}{"field": map[string]interface{}{"type": "string"}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		args         []interface{}
+		kwargs       map[string]interface{}
+		allowUnknown bool
+		want         []string
+	}{
+		{
+			name:   "valid",
+			kwargs: map[string]interface{}{"query": "q", "limit": 3, "score": 0.5, "mode": "fast"},
+		},
+		{
+			name:   "number accepts integers and listed null",
+			kwargs: map[string]interface{}{"query": "q", "score": nil},
+		},
+		{
+			name:   "missing required",
+			kwargs: map[string]interface{}{"limit": 3},
+			want:   []string{`missing required kwarg "query"`},
+		},
+		{
+			name: "required not enforced with positional args",
+			args: []interface{}{"q"},
+		},
+		{
+			name:   "wrong types",
+			kwargs: map[string]interface{}{"query": 1, "limit": 2.5},
+			want:   []string{"limit: expected integer, got number", "query: expected string, got integer"},
+		},
+		{
+			name:   "enum",
+			kwargs: map[string]interface{}{"query": "q", "mode": "slow"},
+			want:   []string{`mode: must be one of ["fast","exact"]`},
+		},
+		{
+			name:   "nested required",
+			kwargs: map[string]interface{}{"query": "q", "filter": map[string]interface{}{}},
+			want:   []string{"filter.field: missing required field"},
+		},
+		{
+			name:   "unknown kwarg suggests a match",
+			kwargs: map[string]interface{}{"query": "q", "limt": 3},
+			want:   []string{`unknown kwarg "limt" (did you mean "limit"?)`},
+		},
+		{
+			name:         "unknown kwarg allowed",
+			kwargs:       map[string]interface{}{"query": "q", "extra": true},
+			allowUnknown: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := checkKwargs(schema, RunInput{InputArgs: tt.args, InputKwargs: tt.kwargs}, tt.allowUnknown)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("checkKwargs = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}